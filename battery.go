@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -27,33 +29,33 @@ const (
 	BatFull
 )
 
-var batBar [5]string
-var batColors [5]status.Color
-var batPrefix map[BatStatus]string
+var batIcons [5]string // icon names
+var batRoles [5]status.Role
 var batStatus map[string]BatStatus
+var batAlt map[BatStatus]string
 
 func init() {
-	batBar = [...]string{
-		"",
-		"",
-		"",
-		"",
-		"",
+	batIcons = [...]string{
+		"battery-empty",
+		"battery-quarter",
+		"battery-half",
+		"battery-three-quarters",
+		"battery-full",
 	}
 
-	batColors = [...]status.Color{
-		status.ColorFromHex("#B82E34"), // very low
-		status.ColorFromHex("#B82E34"), // low
-		status.ColorFromHex("#8A8B8C"), // medium
-		status.ColorFromHex("#8A8B8C"), // near full
-		status.ColorFromHex("#8A8B8C"), // full
+	batRoles = [...]status.Role{
+		status.RoleBad,  // very low
+		status.RoleBad,  // low
+		status.RoleIdle, // medium
+		status.RoleIdle, // near full
+		status.RoleIdle, // full
 	}
 
-	batPrefix = map[BatStatus]string{
-		BatUnknown:     "",
-		BatCharging:    " ",
-		BatDischarging: "",
-		BatFull:        "",
+	batAlt = map[BatStatus]string{
+		BatUnknown:     "unknown",
+		BatCharging:    "charging",
+		BatDischarging: "discharging",
+		BatFull:        "full",
 	}
 
 	batStatus = map[string]BatStatus{
@@ -89,22 +91,88 @@ func batteryStatus(name string) (status BatStatus, charge BatCharge, err error)
 	return
 }
 
+// readMicro reads a sysfs value in micro units (eg. µW), 0 if missing.
+func readMicro(path string) float64 {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0
+	}
+	return v / 1e6
+}
+
+// batteryPower returns the power drawn from or charged into the battery,
+// and the estimated time until it is empty or full. Zero if unknown.
+func batteryPower(name string, status BatStatus) (watts float64, remaining time.Duration) {
+	batPath := fmt.Sprintf("%s/%s/", PowerSupplyPath, name)
+
+	// Batteries report either energy (Wh and W) or charge (Ah and A),
+	// the remaining time is the same in both units.
+	rate := readMicro(batPath + "power_now")
+	now, full := readMicro(batPath+"energy_now"), readMicro(batPath+"energy_full")
+	watts = rate
+	if rate == 0 {
+		rate = readMicro(batPath + "current_now")
+		now, full = readMicro(batPath+"charge_now"), readMicro(batPath+"charge_full")
+		watts = rate * readMicro(batPath+"voltage_now")
+	}
+	if rate == 0 {
+		return
+	}
+
+	switch status {
+	case BatDischarging:
+		remaining = hours(now / rate)
+		break
+	case BatCharging:
+		remaining = hours((full - now) / rate)
+		break
+	}
+	return
+}
+
+func hours(h float64) time.Duration {
+	return time.Duration(h * float64(time.Hour))
+}
+
 // Battery represents the status of a battery
 type Battery struct {
 	Path   string
 	Status BatStatus
 	Charge BatCharge
+
+	// Watts and Remaining are zero if unknown.
+	Watts     float64
+	Remaining time.Duration
+}
+
+// Fields returns the fields of the battery used by templates.
+func (b Battery) Fields(icons status.IconSet) status.Fields {
+	return status.Fields{
+		"name":      b.Path,
+		"charge":    int(b.Charge),
+		"status":    batAlt[b.Status],
+		"symbol":    b.Symbol(icons),
+		"watts":     b.Watts,
+		"remaining": b.Remaining,
+	}
 }
 
-// Color returns a suitable color for the given battery capacity/state.
-func (b Battery) Color() status.Color {
-	return batColors[int(float64(b.Charge)/100.0*float64(len(batColors)-1))]
+// Role returns a suitable color role for the given battery capacity/state.
+func (b Battery) Role() status.Role {
+	return batRoles[int(float64(b.Charge)/100.0*float64(len(batRoles)-1))]
 }
 
 // Symbol returns a suitable symbol for the given battery capacity/state.
-func (b Battery) Symbol() string {
-	symb := batBar[int(float64(b.Charge)/100.0*float64(len(batBar)-1))]
-	return fmt.Sprintf("%s%s", batPrefix[b.Status], symb)
+func (b Battery) Symbol(icons status.IconSet) string {
+	symb := icons.Icon(batIcons[int(float64(b.Charge)/100.0*float64(len(batIcons)-1))])
+	if b.Status == BatCharging {
+		return fmt.Sprintf("%s %s", icons.Icon("battery-charging"), symb)
+	}
+	return symb
 }
 
 // BatteryInfo returns a slice of batteries with name, status and charge.
@@ -127,7 +195,8 @@ func BatteryInfo() ([]Battery, error) {
 			return bats, err
 		}
 
-		bats = append(bats, Battery{name, s, c})
+		watts, remaining := batteryPower(name, s)
+		bats = append(bats, Battery{name, s, c, watts, remaining})
 	}
 	return bats, nil
 }
@@ -135,9 +204,23 @@ func BatteryInfo() ([]Battery, error) {
 type BatteryGen struct {
 	Alignment status.AlignStr
 	Every     time.Duration
+
+	// Icons are used for the symbol, see status.IconSet.
+	Icons status.IconSet
+
+	// Format and ShortFormat replace the default text if set,
+	// see Battery.Fields for the fields.
+	Format      *status.Template
+	ShortFormat *status.Template
+
+	// Gradient colors the charge if set, from 0% to 100%.
+	Gradient status.Gradient
+
+	// Thresholds by charge, replaces the default color roles if set.
+	Thresholds *status.Thresholds
 }
 
-func (b BatteryGen) Generate(w *status.Widget, index int, ctx *status.GeneratorCtx) {
+func (b BatteryGen) Run(ctx context.Context, sink status.Sink) error {
 
 	gen := func() (e []status.Element, err error) {
 		bats, err := BatteryInfo()
@@ -145,16 +228,27 @@ func (b BatteryGen) Generate(w *status.Widget, index int, ctx *status.GeneratorC
 			return
 		}
 		for _, bat := range bats {
-			color := bat.Color()
-			e = append(e, status.Element{Name: "Battery", Instance: bat.Path,
-				Alignment: b.Alignment, Color: &color,
-				FullText: fmt.Sprintf("%d%% %s", bat.Charge, bat.Symbol())})
+			perc := int(bat.Charge)
+			elem := status.Element{Name: "Battery", Instance: bat.Path,
+				Alignment: b.Alignment, ColorRole: bat.Role(), Percentage: &perc,
+				Alt:       batAlt[bat.Status],
+				FullText:  fmt.Sprintf("%d%% %s", bat.Charge, bat.Symbol(b.Icons)),
+				ShortText: fmt.Sprintf("%d%%", bat.Charge)}
+			if len(b.Gradient) > 0 {
+				elem.Color = b.Gradient.Percent(perc)
+				elem.ColorRole = ""
+			}
+			if err := status.ApplyTemplates(&elem, b.Format, b.ShortFormat, bat.Fields(b.Icons)); err != nil {
+				log.Printf("Battery template failed: %v\n", err)
+			} else {
+				b.Thresholds.Apply(bat.Path, float64(bat.Charge), &elem)
+			}
+			e = append(e, elem)
 		}
 		return
 	}
 
-	ticker := time.NewTicker(b.Every)
-	status.Generatorfunc(w, index, ctx, ticker.C, gen)
-	ticker.Stop()
-	return
+	ticker := status.NewAlignedTicker(interval(b.Every))
+	defer ticker.Stop()
+	return status.RunEvery(ctx, sink, ticker.C, gen)
 }
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/jorgenbele/go-status/status"
+)
+
+// runtimePath returns the path of name in $XDG_RUNTIME_DIR, falling
+// back to the temporary directory if it is not set.
+func runtimePath(name string) string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, name)
+}
+
+// SocketPath is the control socket of the running go-status.
+var SocketPath = runtimePath("go-status.sock")
+
+// FIFOPath is the FIFO which the push widget reads pushes from.
+var FIFOPath = runtimePath("go-status.push")
+
+// PidPath is the file which contains the pid of the running go-status.
+var PidPath = runtimePath("go-status.pid")
+
+// writePidFile creates PidPath containing the pid, so the refresh
+// signals can be sent with eg. kill -RTMIN+n. PidPath may be in a
+// shared temporary directory, so symlinks are never followed and an
+// existing file is only replaced if its process is gone.
+func writePidFile() error {
+	for retry := true; ; retry = false {
+		f, err := os.OpenFile(PidPath,
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL|syscall.O_NOFOLLOW, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			return err
+		}
+		if !os.IsExist(err) || !retry {
+			return err
+		}
+
+		pid, err := readPidFile()
+		if err != nil {
+			return err
+		}
+		// EPERM is a process of another user.
+		if err := syscall.Kill(pid, 0); err == nil || err == syscall.EPERM {
+			return fmt.Errorf("%s is used by the running pid %d", PidPath, pid)
+		}
+		os.Remove(PidPath)
+	}
+}
+
+// readPidFile returns the pid in PidPath, without following symlinks.
+func readPidFile() (int, error) {
+	f, err := os.OpenFile(PidPath, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid file %s: %v", PidPath, err)
+	}
+	return pid, nil
+}
+
+// removePidFile removes PidPath, unless it is the file of another
+// go-status which has replaced it.
+func removePidFile() {
+	if pid, err := readPidFile(); err == nil && pid == os.Getpid() {
+		os.Remove(PidPath)
+	}
+}
+
+// refreshCmd forces the widget(s) matching name of the running go-status
+// to regenerate, name is matched like by the refresh control method.
+func refreshCmd(name string) error {
+	req := status.ControlRequest{ID: 1, Method: "refresh",
+		Params: status.ControlParams{Name: name}}
+	resp, err := status.Control(SocketPath, req)
+	if err != nil {
+		return fmt.Errorf("unable to reach running go-status: %v", err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
+
+// ctlCmd sends a single control request built from args to the running
+// go-status and prints the result as JSON.
+func ctlCmd(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("ctl: missing method")
+	}
+
+	req := status.ControlRequest{ID: 1, Method: args[0]}
+	switch req.Method {
+	case "list", "health":
+		break
+
+	case "refresh", "hide", "show":
+		if len(args) != 2 {
+			return fmt.Errorf("ctl %s: expected a widget name", req.Method)
+		}
+		req.Params.Name = args[1]
+
+	case "dismiss":
+		if len(args) > 2 {
+			return fmt.Errorf("ctl dismiss: expected at most a message name")
+		}
+		if len(args) == 2 {
+			req.Params.Name = args[1]
+		}
+
+	case "message":
+		if len(args) < 2 || len(args) > 3 {
+			return fmt.Errorf("ctl message: expected <text> [ttl]")
+		}
+		req.Params.Element = &status.Element{Name: "message",
+			Alignment: status.AlignRight, FullText: args[1]}
+		if len(args) == 3 {
+			ttl, err := strconv.ParseFloat(args[2], 64)
+			if err != nil {
+				return fmt.Errorf("ctl message: invalid ttl: %v", err)
+			}
+			req.Params.TTL = ttl
+		}
+
+	default:
+		return fmt.Errorf("ctl: unknown method %s", req.Method)
+	}
+
+	resp, err := status.Control(SocketPath, req)
+	if err != nil {
+		return fmt.Errorf("unable to reach running go-status: %v", err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	data, err := json.MarshalIndent(resp.Result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// pushCmd pushes an element built from args to the push widget of the
+// running go-status. An empty text removes the element.
+func pushCmd(args []string) error {
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	name := fs.String("name", "push", "name identifying the element")
+	color := fs.String("color", "", "text color (eg. #RRGGBB, #RGBA, red or hsl(0, 100%, 50%))")
+	background := fs.String("background", "", "background color, see --color")
+	urgent := fs.Bool("urgent", false, "mark the element as urgent")
+	ttl := fs.Float64("ttl", 0, "seconds until the element disappears, 0 is forever")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("push: expected a single text argument")
+	}
+
+	e := status.Element{Name: *name, FullText: fs.Arg(0), Urgent: *urgent}
+	if *color != "" {
+		c, err := status.ParseColor(*color)
+		if err != nil {
+			return err
+		}
+		e.Color = &c
+	}
+	if *background != "" {
+		c, err := status.ParseColor(*background)
+		if err != nil {
+			return err
+		}
+		e.Background = &c
+	}
+
+	req := status.ControlRequest{ID: 1, Method: "push",
+		Params: status.ControlParams{Element: &e, TTL: *ttl}}
+	resp, err := status.Control(SocketPath, req)
+	if err != nil {
+		return fmt.Errorf("unable to reach running go-status: %v", err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
+
+// clickCmd sends a click of button on the element with the given name
+// and instance to the running go-status. Used by bars which run a
+// command when an element is clicked.
+func clickCmd(args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return fmt.Errorf("click: expected <button> <name> [instance]")
+	}
+	button, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("click: invalid button: %v", err)
+	}
+
+	req := status.ControlRequest{ID: 1, Method: "click",
+		Params: status.ControlParams{Name: args[1], Button: button}}
+	if len(args) == 3 {
+		req.Params.Instance = args[2]
+	}
+
+	resp, err := status.Control(SocketPath, req)
+	if err != nil {
+		return fmt.Errorf("unable to reach running go-status: %v", err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
+
+// clickCommand returns the command bars should run to send
+// a click to this go-status.
+func clickCommand() string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "go-status"
+	}
+	return exe + " click"
+}
@@ -1,24 +1,137 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 	"github.com/jorgenbele/go-status/status"
 )
 
+// ClockGen shows the time in one or more time zones. The formats are
+// either time.Format layouts or strftime(3) formats, see status.FormatTime.
+// Left click switches to the next of Format and AltFormats, and with
+// CycleZones right click switches to the next zone.
 type ClockGen struct {
-	Format    string
+	Format      string
+	AltFormats  []string
+	ShortFormat string // used when short on space, optional
+
+	// Zones are IANA time zone names (eg. Europe/Oslo), the local
+	// zone is used if empty. Every zone is shown as a separate
+	// element, or one at a time with CycleZones.
+	Zones      []string
+	CycleZones bool
+
+	// Calendar shows a calendar of the month as tooltip.
+	Calendar bool
+
 	Alignment status.AlignStr
-	Every     time.Duration
+
+	// Every is the update interval, the clock is updated on the
+	// boundaries of it, see status.AlignedTicker. Defaults to a second.
+	Every time.Duration
+
+	mu     sync.Mutex
+	format int
+	zone   int
 }
 
-func (c ClockGen) Generate(w *status.Widget, index int, ctx *status.GeneratorCtx) {
+// Click implements status.Clicker.
+func (c *ClockGen) Click(ev status.ClickEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch ev.Button {
+	case 1:
+		c.format = (c.format + 1) % (len(c.AltFormats) + 1)
+		break
+	case 3:
+		if len(c.Zones) > 0 {
+			c.zone = (c.zone + 1) % len(c.Zones)
+		}
+		break
+	}
+}
+
+// locations loads the zones, or returns the local zone if there are none.
+func (c *ClockGen) locations() (locs []*time.Location, err error) {
+	if len(c.Zones) == 0 {
+		return []*time.Location{time.Local}, nil
+	}
+	for _, zone := range c.Zones {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, err
+		}
+		locs = append(locs, loc)
+	}
+	return
+}
+
+// calendar returns a calendar of the month of t, starting on monday.
+func calendar(t time.Time) string {
+	var b strings.Builder
+	title := t.Format("January 2006")
+	fmt.Fprintf(&b, "%*s\n", (20+len(title))/2, title)
+	b.WriteString("Mo Tu We Th Fr Sa Su\n")
+
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	offset := (int(first.Weekday()) + 6) % 7
+	b.WriteString(strings.Repeat("   ", offset))
+	for d := first; d.Month() == t.Month(); d = d.AddDate(0, 0, 1) {
+		fmt.Fprintf(&b, "%2d", d.Day())
+		if d.Weekday() == time.Sunday {
+			b.WriteString("\n")
+		} else {
+			b.WriteString(" ")
+		}
+	}
+	return strings.TrimRight(b.String(), " \n")
+}
+
+func (c *ClockGen) Run(ctx context.Context, sink status.Sink) error {
+	locs, err := c.locations()
+	if err != nil {
+		return err
+	}
+
 	gen := func() (e []status.Element, err error) {
-		t := time.Now()
-		fmt := t.Format(c.Format)
-		e = append(e, status.Element{Name: "Clock", Alignment: c.Alignment, FullText: fmt})
+		c.mu.Lock()
+		format := c.Format
+		if c.format > 0 {
+			format = c.AltFormats[c.format-1]
+		}
+		shown := locs
+		if c.CycleZones {
+			shown = locs[c.zone : c.zone+1]
+		}
+		c.mu.Unlock()
+
+		now := time.Now()
+		for _, loc := range shown {
+			t := now.In(loc)
+			elem := status.Element{Name: "Clock", Alignment: c.Alignment,
+				FullText: status.FormatTime(t, format)}
+			if len(c.Zones) > 0 {
+				elem.Instance = loc.String()
+			}
+			if c.ShortFormat != "" {
+				elem.ShortText = status.FormatTime(t, c.ShortFormat)
+			}
+			if c.Calendar {
+				elem.Tooltip = calendar(t)
+			}
+			e = append(e, elem)
+		}
 		return
 	}
-	ticker := time.NewTicker(c.Every)
-	status.Generatorfunc(w, index, ctx, ticker.C, gen)
-	ticker.Stop()
+	every := c.Every
+	if every <= 0 {
+		every = time.Second
+	}
+	ticker := status.NewAlignedTicker(every)
+	defer ticker.Stop()
+	return status.RunEvery(ctx, sink, ticker.C, gen)
 }
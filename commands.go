@@ -13,8 +13,22 @@ import (
 	"github.com/jorgenbele/go-status/status"
 )
 
+// DefaultEvery is the update interval of the widgets without one.
+const DefaultEvery = 10 * time.Second
+
+// interval returns every, or DefaultEvery if it is not positive.
+func interval(every time.Duration) time.Duration {
+	if every <= 0 {
+		return DefaultEvery
+	}
+	return every
+}
+
 type CmdGen struct {
-	C          <-chan time.Time
+	// Every is the interval between runs of the command, aligned to
+	// the wall clock (see status.AlignedTicker). Defaults to DefaultEvery.
+	Every time.Duration
+
 	Instance   string
 	CmdCreator func() *exec.Cmd
 	IsJSON     bool
@@ -74,7 +88,9 @@ func (c CmdGen) Generate(w *status.Widget, index int, ctx *status.GeneratorCtx)
 		}
 		return
 	}
-	status.Generatorfunc(w, index, ctx, c.C, gen)
+	ticker := status.NewAlignedTicker(interval(c.Every))
+	defer ticker.Stop()
+	status.Generatorfunc(w, index, ctx, ticker.C, gen)
 }
 
 // StreamingCmdGen reads from JSON on a line by line
@@ -91,7 +107,7 @@ func (c StreamingCmdGen) Generate(w *status.Widget, index int, ctx *status.Gener
 	failnow := func(err error) {
 		log.Printf("Command failed for widget #%d: %s\n", index, err)
 		w.Error = err
-		ctx.Errorch <- status.WidgetError{index, err}
+		ctx.Errorch <- status.WidgetError{Index: index, Error: err}
 		ctx.Done <- true
 		return
 	}
@@ -119,19 +135,51 @@ func (c StreamingCmdGen) Generate(w *status.Widget, index int, ctx *status.Gener
 				close(ch)
 				return
 			}
-			ticker <- time.Now()
+			// Queue the line before ticking, so it is available
+			// to gen() when the tick is consumed.
 			ch <- bytes
+			ticker <- time.Now()
 		}
 	}(stdoutch, tickerch, stdout)
 
+	var last []status.Element
+	first := true
 	gen := func() (e []status.Element, err error) {
+		var line []byte
+		if first {
+			// Wait for the first line, the command may never
+			// write one so stopping must not wait for it.
+			first = false
+			select {
+			case line = <-stdoutch:
+				break
+			case <-ctx.Stop:
+				if cmd.Process != nil {
+					cmd.Process.Kill()
+				}
+				// Leave the stop to Generatorfunc.
+				ctx.Stop <- true
+				return nil, nil
+			}
+		} else {
+			select {
+			case line = <-stdoutch:
+				break
+			default:
+				// Refreshed without any new output, there is
+				// nothing to do but repeat the last line.
+				return last, nil
+			}
+		}
+
 		var elem status.Element
-		err = json.Unmarshal(<-stdoutch, &elem)
+		err = json.Unmarshal(line, &elem)
 		if err != nil {
 			//failnow(err)
 			return
 		}
 		e = append(e, elem)
+		last = e
 		return
 	}
 	status.Generatorfunc(w, index, ctx, tickerch, gen)
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jorgenbele/go-status/status"
+)
+
+// Config is read from the JSON config file at ConfigPath, eg:
+//
+//	{"widgets": {"battery": {"thresholds": {"hysteresis": 2, "rules": [
+//		{"max": 15, "color": "#B82E34", "urgent": true},
+//		{"max": 50, "color_role": "degraded"}]}},
+//	 "cpu": {"gradient": ["green", "yellow", "red"], "icon_set": "ascii",
+//		"format": "{{lpad 3 .usage}}% {{fixed 2 .load5}}"}}}
+type Config struct {
+	Widgets map[string]WidgetConfig `json:"widgets"`
+
+	// Theme is the name of the theme, either one of Themes
+	// or one of the bundled themes.
+	Theme string `json:"theme,omitempty"`
+
+	// Themes are custom themes, roles which are missing are
+	// taken from the default theme.
+	Themes map[string]status.Theme `json:"themes,omitempty"`
+
+	// IconSet is the name of the bundled icon set used by all widgets,
+	// and Icons replace single icons of it.
+	IconSet string         `json:"icon_set,omitempty"`
+	Icons   status.IconSet `json:"icons,omitempty"`
+}
+
+// WidgetConfig configures the widget with the same name.
+type WidgetConfig struct {
+	Thresholds *status.Thresholds `json:"thresholds,omitempty"`
+	Gradient   status.Gradient    `json:"gradient,omitempty"`
+
+	// IconSet and Icons override the icons of the config.
+	IconSet string         `json:"icon_set,omitempty"`
+	Icons   status.IconSet `json:"icons,omitempty"`
+
+	// Zones are the time zones of the clock.
+	Zones []string `json:"zones,omitempty"`
+
+	// Format and ShortFormat are templates of the text of the
+	// widget, see status.Template.
+	Format      *status.Template `json:"format,omitempty"`
+	ShortFormat *status.Template `json:"short_format,omitempty"`
+}
+
+// configPath returns the path of the config file, which is
+// $GO_STATUS_CONFIG or go-status/config.json in $XDG_CONFIG_HOME.
+func configPath() string {
+	if path := os.Getenv("GO_STATUS_CONFIG"); path != "" {
+		return path
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, "go-status", "config.json")
+}
+
+// ConfigPath is the config file, it is optional.
+var ConfigPath = configPath()
+
+// loadConfig reads the config file at path, a missing
+// file results in an empty config.
+func loadConfig(path string) (cfg Config, err error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		err = fmt.Errorf("%s: %v", path, err)
+	}
+	return
+}
+
+// LookupTheme returns the named theme, custom themes
+// take precedence over the bundled themes.
+func (c Config) LookupTheme(name string) (status.Theme, error) {
+	custom, ok := c.Themes[name]
+	if !ok {
+		return status.LookupTheme(name)
+	}
+	t := status.Theme{}
+	for role, color := range status.DefaultTheme {
+		t[role] = color
+	}
+	for role, color := range custom {
+		t[role] = color
+	}
+	return t, nil
+}
+
+// Thresholds returns the thresholds of the named widget, or nil.
+func (c Config) Thresholds(name string) *status.Thresholds {
+	return c.Widgets[name].Thresholds
+}
+
+// Gradient returns the gradient of the named widget, or nil.
+func (c Config) Gradient(name string) status.Gradient {
+	return c.Widgets[name].Gradient
+}
+
+// WidgetIcons returns the icons of the named widget, which are the
+// icon set of the widget or config, with the icons of the config
+// and then the widget replacing those of the set.
+func (c Config) WidgetIcons(name string) (status.IconSet, error) {
+	w := c.Widgets[name]
+
+	set := status.DefaultIconSet
+	for _, setName := range []string{c.IconSet, w.IconSet} {
+		if setName == "" {
+			continue
+		}
+		var err error
+		if set, err = status.LookupIconSet(setName); err != nil {
+			return nil, err
+		}
+	}
+	return set.With(c.Icons).With(w.Icons), nil
+}
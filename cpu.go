@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +25,10 @@ type CPU struct {
 	// CPU Usage as a fraction.
 	Usage float64
 	Cores uint
+
+	// Load averages over 5 and 15 minutes, Usage is over 1 minute.
+	Load5  float64
+	Load15 float64
 }
 
 func cores() (n uint, err error) {
@@ -65,9 +71,11 @@ func CPUInfo() (cpu CPU, err error) {
 		return
 	}
 
-	cpu.Usage, err = strconv.ParseFloat(l[0], 64)
-	if err != nil {
-		return
+	for i, v := range []*float64{&cpu.Usage, &cpu.Load5, &cpu.Load15} {
+		*v, err = strconv.ParseFloat(l[i], 64)
+		if err != nil {
+			return
+		}
 	}
 	cpu.Cores, err = cores()
 	return // includes err
@@ -77,34 +85,84 @@ func (c CPU) UsagePerc() int {
 	return int(c.Usage / float64(c.Cores) * 100.0)
 }
 
-func (c CPU) Color() status.Color {
-	return status.ColorFromHex("#8A8B8C")
+// Fields returns the fields of the CPU used by templates.
+func (c CPU) Fields(icons status.IconSet) status.Fields {
+	return status.Fields{
+		"usage":  c.UsagePerc(),
+		"cores":  c.Cores,
+		"load1":  c.Usage,
+		"load5":  c.Load5,
+		"load15": c.Load15,
+		"symbol": c.Symbol(icons),
+	}
+}
+
+func (c CPU) Role() status.Role {
+	return status.RoleIdle
+}
+
+const cpuBarSize = 5
+
+func (c CPU) Symbol(icons status.IconSet) string {
+	return status.HBarText(c.barProgress(), cpuBarSize, icons.Icon("bar-full"), icons.Icon("bar-empty"))
 }
 
-func (c CPU) Symbol() string {
-	size := 5
-	return status.HBar(int(c.Usage/float64(c.Cores)*float64(size)), size, '+', '-')
+// SymbolSpans is like Symbol, but colored by g.
+func (c CPU) SymbolSpans(icons status.IconSet, g status.Gradient) []status.Span {
+	return status.HBarSpans(c.barProgress(), cpuBarSize, icons.Icon("bar-full"), icons.Icon("bar-empty"), g)
+}
+
+func (c CPU) barProgress() int {
+	return int(c.Usage / float64(c.Cores) * float64(cpuBarSize))
 }
 
 // CPUGen gets the CPU utilization by reading the /proc/loadavg file.
 type CPUGen struct {
 	Alignment status.AlignStr
 	Every     time.Duration
+
+	// Icons are used for the symbol, see status.IconSet.
+	Icons status.IconSet
+
+	// Format and ShortFormat replace the default text if set,
+	// see CPU.Fields for the fields.
+	Format      *status.Template
+	ShortFormat *status.Template
+
+	// Gradient colors the usage if set, from 0% to 100%.
+	Gradient status.Gradient
+
+	// Thresholds by usage in percent, replaces the default color role if set.
+	Thresholds *status.Thresholds
 }
 
-// Generate ...
-func (c CPUGen) Generate(w *status.Widget, index int, ctx *status.GeneratorCtx) {
+// Run ...
+func (c CPUGen) Run(ctx context.Context, sink status.Sink) error {
 	gen := func() (e []status.Element, err error) {
 		cpu, err := CPUInfo()
 		if err != nil {
 			return
 		}
-		color := cpu.Color()
-		e = append(e, status.Element{Name: "CPU", Alignment: c.Alignment, Color: &color,
-			FullText: fmt.Sprintf("%d%% %s", cpu.UsagePerc(), cpu.Symbol())})
+		perc := cpu.UsagePerc()
+		elem := status.Element{Name: "CPU", Alignment: c.Alignment, ColorRole: cpu.Role(),
+			Percentage: &perc,
+			FullText:   fmt.Sprintf("%d%% %s", cpu.UsagePerc(), cpu.Symbol(c.Icons)),
+			ShortText:  fmt.Sprintf("%d%%", cpu.UsagePerc())}
+		if len(c.Gradient) > 0 {
+			elem.Color = c.Gradient.Percent(perc)
+			elem.ColorRole = ""
+			elem.Spans = append([]status.Span{{Text: fmt.Sprintf("%d%% ", perc)}},
+				cpu.SymbolSpans(c.Icons, c.Gradient)...)
+		}
+		if err := status.ApplyTemplates(&elem, c.Format, c.ShortFormat, cpu.Fields(c.Icons)); err != nil {
+			log.Printf("CPU template failed: %v\n", err)
+		} else {
+			c.Thresholds.Apply("", float64(perc), &elem)
+		}
+		e = append(e, elem)
 		return
 	}
-	ticker := time.NewTicker(c.Every)
-	status.Generatorfunc(w, index, ctx, ticker.C, gen)
-	ticker.Stop()
+	ticker := status.NewAlignedTicker(interval(c.Every))
+	defer ticker.Stop()
+	return status.RunEvery(ctx, sink, ticker.C, gen)
 }
@@ -2,106 +2,325 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"os/exec"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 	"github.com/jorgenbele/go-status/status"
 )
 
 func main() {
-	// NOTE: time.Tick(...) is used instead of time.NewTicker(...) because
-	// the program will be shutting down when the Widget is shutting down.
+	cfg, err := loadConfig(ConfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	icons := func(widget string) status.IconSet {
+		set, err := cfg.WidgetIcons(widget)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return set
+	}
+
+	// The FIFO is only read by the daemon, see below.
+	push := NewPushGen("", status.AlignRight)
+
 	widgets := []status.Widget{
-		status.Widget{
-			Gen: CmdGen{Instance: "spotify",
-				C:      time.Tick(time.Second * 10),
+		status.Widget{Name: "spotify", Signal: 1, Priority: 1,
+			Gen: status.Marquee(status.Legacy(CmdGen{Instance: "spotify",
+				Every:  time.Second * 10,
 				IsJSON: true,
 				CmdCreator: func() *exec.Cmd {
 					return exec.Command("spotifystatus", "--json")
-				}}},
+				}}), status.MarqueeOptions{
+				Width:     32,
+				Every:     time.Millisecond * 300,
+				Pause:     10,
+				Separator: "  ·  ",
+			})},
 
-		status.Widget{Gen: StreamingCmdGen{
+		status.Widget{Name: "nmcliwatcher", Priority: 3, Gen: StreamingCmdGen{
 			Instance: "nmcliwatcher",
 			CmdCreator: func() *exec.Cmd {
 				return exec.Command("nm_watcher", "wlp3s0")
 			}}},
 
-		status.Widget{Gen: StreamingCmdGen{
+		status.Widget{Name: "mullvadwatcher", Priority: 2, Gen: StreamingCmdGen{
 			Instance: "mullvadwatcher",
 			CmdCreator: func() *exec.Cmd {
 				return exec.Command("mullvad_watcher")
 			}}},
 
-		status.Widget{
+		status.Widget{Name: "mullvadvpn", Signal: 2, Priority: 2,
 			Gen: CmdGen{Instance: "mullvadvpn",
-				C:      time.Tick(time.Second * 10),
+				Every:  time.Second * 10,
 				IsJSON: true,
 				CmdCreator: func() *exec.Cmd {
 					return exec.Command("mullvad_jsonblock")
 				}}},
 
-		status.Widget{Gen: BatteryGen{
-			Alignment: status.AlignRight,
-			Every:     time.Second * 10,
+		status.Widget{Name: "push", Priority: 9,
+			Gen: push},
+
+		status.Widget{Name: "battery", Signal: 3, Priority: 8, Gen: BatteryGen{
+			Alignment:   status.AlignRight,
+			Every:       time.Second * 10,
+			Icons:       icons("battery"),
+			Format:      cfg.Widgets["battery"].Format,
+			ShortFormat: cfg.Widgets["battery"].ShortFormat,
+			Gradient:    cfg.Gradient("battery"),
+			Thresholds:  cfg.Thresholds("battery"),
 		}},
 
-		status.Widget{Gen: CPUGen{
-			Alignment: status.AlignRight,
-			Every:     time.Second * 10,
+		status.Widget{Name: "cpu", Signal: 4, Priority: 5, Gen: CPUGen{
+			Alignment:   status.AlignRight,
+			Every:       time.Second * 10,
+			Icons:       icons("cpu"),
+			Format:      cfg.Widgets["cpu"].Format,
+			ShortFormat: cfg.Widgets["cpu"].ShortFormat,
+			Gradient:    cfg.Gradient("cpu"),
+			Thresholds:  cfg.Thresholds("cpu"),
 		}},
 
-		status.Widget{Gen: ClockGen{
-			Format:    "Mon Jan 2 15:04:05",
-			Alignment: status.AlignRight,
-			Every:     time.Second,
+		status.Widget{Name: "clock", Signal: 5, Priority: 10, Gen: &ClockGen{
+			Format:      "Mon Jan 2 15:04:05",
+			AltFormats:  []string{"%F %T, week %V"},
+			ShortFormat: "15:04",
+			Zones:       cfg.Widgets["clock"].Zones,
+			CycleZones:  true,
+			Calendar:    true,
+			Alignment:   status.AlignRight,
+			Every:       time.Second,
 		}},
 	}
 
-	out := bufio.NewWriter(os.Stdout)
+	out := status.NewDedupWriter(bufio.NewWriter(os.Stdout))
 	defer out.Flush()
 
 	usage := func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [--format (i3bar | lemonbar | dzen2)]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [--format (i3bar | lemonbar | dzen2 | polybar | waybar |\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "           xmobar | dwm | tmux | term | plain)] [--widget <name>] [--once]\n")
+		fmt.Fprintf(os.Stderr, "           [--tmux-option <option>] [--width <pixels> --char-width <pixels>]\n")
+		fmt.Fprintf(os.Stderr, "           [--columns <n>] [--theme <name>]\n")
+		fmt.Fprintf(os.Stderr, "       %s refresh <widget>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s ctl (list | health | refresh <widget> | hide <widget> |\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "           show <widget> | message <text> [ttl] | dismiss [name])\n")
+		fmt.Fprintf(os.Stderr, "       %s push [--name <name>] [--color <color>] [--background <color>]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "           [--urgent] [--ttl <seconds>] [text]\n")
+		fmt.Fprintf(os.Stderr, "       %s click <button> <name> [instance]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nIf no --format <bar> is specified then i3bar is used.\n")
+		fmt.Fprintf(os.Stderr, "With --widget only the named widget is run, eg. for waybar modules.\n")
+		fmt.Fprintf(os.Stderr, "With --once every widget is run once, the bar is written and go-status exits.\n")
+		fmt.Fprintf(os.Stderr, "With --tmux-option (eg. status-right) the tmux option is set on every update.\n")
+		fmt.Fprintf(os.Stderr, "With --width and --char-width dzen2 elements are aligned to the center and right.\n")
+		fmt.Fprintf(os.Stderr, "With --columns the elements are shortened to fit within n columns.\n")
+		fmt.Fprintf(os.Stderr, "With --theme the colors of the theme are used, the bundled themes are:\n")
+		fmt.Fprintf(os.Stderr, "    %s\n", strings.Join(status.ThemeNames(), ", "))
+		fmt.Fprintf(os.Stderr, "\nThe widgets are configured in %s.\n", ConfigPath)
+		fmt.Fprintf(os.Stderr, "The bundled icon sets are: %s\n", strings.Join(status.IconSetNames(), ", "))
 		os.Exit(1)
 	}
 
+	subcommands := map[string]func(args []string) error{
+		"refresh": func(args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("refresh: expected a widget name")
+			}
+			return refreshCmd(args[0])
+		},
+		"ctl":   ctlCmd,
+		"push":  pushCmd,
+		"click": clickCmd,
+	}
+	if len(os.Args) >= 2 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	fs.Usage = usage
+	format := fs.String("format", "i3bar", "")
+	only := fs.String("widget", "", "")
+	tmuxOption := fs.String("tmux-option", "", "")
+	once := fs.Bool("once", false, "")
+	width := fs.Int("width", 0, "")
+	charWidth := fs.Int("char-width", 0, "")
+	columns := fs.Int("columns", 0, "")
+	theme := fs.String("theme", cfg.Theme, "")
+	if fs.Parse(os.Args[1:]) != nil || fs.NArg() != 0 {
+		usage()
+	}
+
+	// Only run the named widget, used by bars which
+	// run one process per module.
+	if *only != "" {
+		var filtered []status.Widget
+		for _, w := range widgets {
+			if w.Name == *only {
+				filtered = append(filtered, w)
+			}
+		}
+		if len(filtered) == 0 {
+			fmt.Fprintf(os.Stderr, "%s: no widget named %s\n", os.Args[0], *only)
+			os.Exit(1)
+		}
+		widgets = filtered
+	}
+
+	t := status.DefaultTheme
+	if *theme != "" {
+		t, err = cfg.LookupTheme(*theme)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// The separator of the bars which have one, sepWidth is the width
+	// of what is written between two elements, used by --columns.
+	sep := " | "
+	sepColor := t.Color(status.RoleSeparator)
+	sepWidth := 0
+	budgetWidth := *columns
+
 	var b status.Bar
-	if len(os.Args) == 3 && os.Args[1] == "--format" {
-		switch os.Args[2] {
-		case "lemonbar":
-			b = status.NewLemonbar(out)
-			break
-
-		case "dzen2":
-			b = status.NewDzen2Bar(out)
-			break
-
-		case "i3bar":
-			b = status.NewI3Bar(status.I3BarHeader{Version: 1}, out)
-			break
-
-		default:
-			usage()
+	switch *format {
+	case "lemonbar":
+		b = status.NewLemonbar(out)
+		break
+
+	case "dzen2":
+		b = status.NewDzen2(status.Dzen2Options{
+			Separator: sep, SeparatorColor: sepColor,
+			Width: *width, CharWidth: *charWidth,
+			ProgressWidth: 30, ProgressHeight: 8,
+			ClickCommand: clickCommand()}, out)
+		break
+
+	case "polybar":
+		opts := status.PolybarOptions{Padding: 1, ClickCommand: clickCommand()}
+		b = status.NewPolybar(opts, out)
+		// The padding is on both sides of every element,
+		// so also before the first and after the last.
+		sepWidth = 2 * opts.Padding
+		budgetWidth -= sepWidth
+		break
+
+	case "waybar":
+		b = status.NewWaybar(out)
+		break
+
+	case "xmobar":
+		b = status.NewXmobar(status.XmobarOptions{
+			Separator: sep, SeparatorColor: sepColor,
+			ClickCommand: clickCommand()}, out)
+		sepWidth = status.TextWidth(sep)
+		break
+
+	case "tmux":
+		b = status.NewTmux(status.TmuxOptions{
+			Separator: sep, SeparatorColor: sepColor,
+			Option: *tmuxOption}, out)
+		sepWidth = status.TextWidth(sep)
+		break
+
+	case "plain":
+		b = status.NewPlain(sep, out)
+		sepWidth = status.TextWidth(sep)
+		break
+
+	case "term":
+		b = status.NewTerm(int(os.Stdout.Fd()), out)
+		break
+
+	case "dwm":
+		b, err = status.NewDwm(status.DwmOptions{
+			Separator: sep, SeparatorColor: sepColor, Status2d: true})
+		if err != nil {
+			log.Fatal(err)
 		}
-	} else if len(os.Args) == 1 {
-		b = status.NewI3Bar(status.I3BarHeader{Version: 1}, out)
-	} else {
+		sepWidth = status.TextWidth(sep)
+		break
+
+	case "i3bar":
+		b = status.NewI3Bar(status.I3BarHeader{Version: 1, ClickEvents: true}, out)
+		break
+
+	default:
 		usage()
 	}
 
-	s := status.NewStatus(b)
+	// Bars which hold resources, eg. the X connection of dwm.
+	closer, _ := b.(io.Closer)
 
-	for _, w := range widgets {
-		s.AddWidget(w)
+	// i3bar uses the short_text itself, and term and dzen2
+	// know their own width.
+	if *columns > 0 && *format != "i3bar" && *format != "term" && *format != "dzen2" {
+		b = status.NewBudgetBar(b, budgetWidth, sepWidth)
 	}
 
-	sigtermch := make(chan os.Signal)
+	sigtermch := make(chan os.Signal, 1)
 	signal.Notify(sigtermch, os.Interrupt, syscall.SIGTERM)
-	s.SetTermSignal(sigtermch)
+
+	// Get EPIPE instead of being killed when the bar exits,
+	// so that the widgets are shut down cleanly.
+	signal.Ignore(syscall.SIGPIPE)
+
+	opts := []status.Option{
+		status.WithWidgets(widgets...),
+		status.WithTermSignal(sigtermch),
+		status.WithRedrawInterval(50 * time.Millisecond),
+	}
+	if *once {
+		opts = append(opts, status.WithOnce())
+	}
+	if *format == "i3bar" {
+		clickch := make(chan status.ClickEvent)
+		go func() {
+			if err := status.ReadI3BarClicks(os.Stdin, clickch); err != nil {
+				log.Printf("Unable to read click events: %v\n", err)
+			}
+		}()
+		opts = append(opts, status.WithClickEvents(clickch))
+	}
+	if *theme != "" {
+		opts = append(opts, status.WithTheme(t))
+	}
+	// A single widget or one-shot process is not the
+	// go-status the clients want to reach.
+	daemon := *only == "" && !*once
+	if daemon {
+		opts = append(opts, status.WithControlSocket(SocketPath))
+		push.FIFO = FIFOPath
+	}
+
+	s, err := status.New(b, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if sigs := s.RefreshSignals(); len(sigs) > 0 {
+		sigrefreshch := make(chan os.Signal, 1)
+		signal.Notify(sigrefreshch, sigs...)
+		s.SetRefreshSignal(sigrefreshch)
+	}
+
+	if daemon {
+		if err := writePidFile(); err != nil {
+			log.Printf("Unable to write pid file: %v\n", err)
+		}
+	}
 
 	//sigstopch := make(chan os.Signal)
 	//signal.Notify(sigstopch, os.Interrupt, syscall.SIGTSTP)
@@ -111,5 +330,14 @@ func main() {
 	//signal.Notify(sigcontch, os.Interrupt, syscall.SIGCONT)
 	//status.SetContSignal(sigcontch)
 
-	s.Start()
+	err = s.Run(context.Background())
+	if daemon {
+		removePidFile()
+	}
+	if closer != nil {
+		closer.Close()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 }
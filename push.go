@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jorgenbele/go-status/status"
+)
+
+type pushed struct {
+	e       status.Element
+	expires time.Time // zero if it never expires
+}
+
+// PushGen displays elements pushed by external programs, either through
+// the control socket or by writing JSON encoded status.ControlParams
+// (one per line) to FIFO. Elements are identified by their Name, pushing
+// an element with an empty FullText removes it.
+type PushGen struct {
+	FIFO      string
+	Alignment status.AlignStr
+
+	mu      sync.Mutex
+	elems   []pushed
+	changed chan time.Time
+}
+
+// NewPushGen creates a PushGen which also reads pushes from fifo,
+// unless it is empty.
+func NewPushGen(fifo string, align status.AlignStr) *PushGen {
+	return &PushGen{FIFO: fifo, Alignment: align, changed: make(chan time.Time, 1)}
+}
+
+func (p *PushGen) notify() {
+	select {
+	case p.changed <- time.Now():
+	default:
+	}
+}
+
+// Push implements status.Pusher.
+func (p *PushGen) Push(e status.Element, ttl time.Duration) {
+	if e.Alignment == status.AlignNone {
+		e.Alignment = p.Alignment
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := 0
+	for ; i < len(p.elems); i++ {
+		if p.elems[i].e.Name == e.Name {
+			break
+		}
+	}
+
+	if e.FullText == "" {
+		if i < len(p.elems) {
+			p.elems = append(p.elems[:i], p.elems[i+1:]...)
+		}
+		p.notify()
+		return
+	}
+
+	pe := pushed{e: e}
+	if ttl > 0 {
+		pe.expires = time.Now().Add(ttl)
+		time.AfterFunc(ttl, p.notify)
+	}
+	if i < len(p.elems) {
+		p.elems[i] = pe
+	} else {
+		p.elems = append(p.elems, pe)
+	}
+	p.notify()
+}
+
+// makeFIFO creates the FIFO, an existing FIFO is reused and created is
+// false. Anything else at the path is an error, reading a regular file
+// would never block.
+func (p *PushGen) makeFIFO() (created bool, err error) {
+	err = syscall.Mkfifo(p.FIFO, 0600)
+	if err == nil || !os.IsExist(err) {
+		return err == nil, err
+	}
+	fi, err := os.Lstat(p.FIFO)
+	if err != nil {
+		return false, err
+	}
+	if fi.Mode()&os.ModeNamedPipe == 0 {
+		return false, fmt.Errorf("%s exists and is not a fifo", p.FIFO)
+	}
+	return false, nil
+}
+
+// readFIFO reads pushes from the FIFO until ctx is cancelled
+// or it can no longer be opened.
+func (p *PushGen) readFIFO(ctx context.Context) {
+	for ctx.Err() == nil {
+		// Blocks until a writer opens the FIFO, and reaches
+		// EOF when the last writer closes it.
+		f, err := os.Open(p.FIFO)
+		if err != nil {
+			log.Printf("Unable to open push fifo %s: %v\n", p.FIFO, err)
+			return
+		}
+
+		r := bufio.NewScanner(f)
+		for r.Scan() {
+			var params status.ControlParams
+			err = json.Unmarshal(r.Bytes(), &params)
+			if err != nil || params.Element == nil {
+				log.Printf("Invalid push on fifo %s: %s\n", p.FIFO, r.Text())
+				continue
+			}
+			p.Push(*params.Element, time.Duration(params.TTL*float64(time.Second)))
+		}
+		f.Close()
+	}
+}
+
+// closeFIFO wakes up readFIFO if it is waiting for a writer by opening
+// the FIFO for writing, and removes the FIFO if it was created by
+// makeFIFO. A reused FIFO may still be read by someone else.
+func (p *PushGen) closeFIFO(created bool) {
+	f, err := os.OpenFile(p.FIFO, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if created {
+		os.Remove(p.FIFO)
+	}
+	if err == nil {
+		f.Close()
+	}
+}
+
+// Run displays the pushed elements which have not yet expired.
+func (p *PushGen) Run(ctx context.Context, sink status.Sink) error {
+	if p.FIFO != "" {
+		created, err := p.makeFIFO()
+		if err != nil {
+			log.Printf("Unable to create push fifo %s: %v\n", p.FIFO, err)
+		} else {
+			go p.readFIFO(ctx)
+			defer p.closeFIFO(created)
+		}
+	}
+
+	gen := func() (e []status.Element, err error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		now := time.Now()
+		kept := p.elems[:0]
+		for _, pe := range p.elems {
+			if pe.expires.IsZero() || pe.expires.After(now) {
+				kept = append(kept, pe)
+				e = append(e, pe.e)
+			}
+		}
+		p.elems = kept
+		return
+	}
+	return status.RunEvery(ctx, sink, p.changed, gen)
+}
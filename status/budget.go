@@ -0,0 +1,148 @@
+package status
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Ellipsis is appended to truncated text.
+const Ellipsis = "…"
+
+// minTruncated is the minimum width text is truncated to, including
+// the ellipsis, before the element is hidden instead.
+const minTruncated = 4
+
+// TextWidth returns the width of s in columns, wide characters such
+// as emoji and CJK take up two columns.
+func TextWidth(s string) int {
+	return uniseg.StringWidth(s)
+}
+
+// Truncate returns s truncated to at most width columns, ending with
+// an Ellipsis if it was truncated.
+func Truncate(s string, width int) string {
+	if TextWidth(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	n := 0
+	clusters, widths := graphemes(s)
+	for i, cluster := range clusters {
+		if n+widths[i] > width-TextWidth(Ellipsis) {
+			break
+		}
+		b.WriteString(cluster)
+		n += widths[i]
+	}
+	return b.String() + Ellipsis
+}
+
+// Budget degrades the elements of v to make their combined width,
+// including a separator of sepWidth columns between each, fit within
+// width columns. The elements with the lowest Priority are degraded
+// first, and later elements before earlier ones with the same priority:
+// first by using their ShortText, then by truncating them and finally
+// by hiding them.
+func Budget(v []Element, width, sepWidth int) []Element {
+	return budget(v, width, sepWidth, nil)
+}
+
+// budget is Budget, where extra (if not nil) returns the columns taken
+// up by an element in addition to its text, eg. by a progress bar.
+func budget(v []Element, width, sepWidth int, extra func(Element) int) []Element {
+	out := make([]Element, len(v))
+	copy(out, v)
+
+	hidden := make([]bool, len(out))
+	total := func() (w int) {
+		n := 0
+		for i, e := range out {
+			if !hidden[i] {
+				w += TextWidth(e.Text())
+				if extra != nil {
+					w += extra(e)
+				}
+				n++
+			}
+		}
+		if n > 1 {
+			w += (n - 1) * sepWidth
+		}
+		return
+	}
+
+	// Indexes in the order they are degraded.
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = len(out) - 1 - i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return out[order[i]].Priority < out[order[j]].Priority
+	})
+
+	setText := func(i int, text string) {
+		out[i].FullText = text
+		out[i].Spans = nil
+	}
+
+	for _, i := range order {
+		if total() <= width {
+			return out
+		}
+		if out[i].ShortText != "" && TextWidth(out[i].ShortText) < TextWidth(out[i].Text()) {
+			setText(i, out[i].ShortText)
+		}
+	}
+
+	for _, i := range order {
+		over := total() - width
+		if over <= 0 {
+			return out
+		}
+		w := TextWidth(out[i].Text())
+		if w-over >= minTruncated {
+			setText(i, Truncate(out[i].Text(), w-over))
+		} else if w > minTruncated {
+			setText(i, Truncate(out[i].Text(), minTruncated))
+		}
+	}
+
+	for _, i := range order {
+		if total() <= width {
+			break
+		}
+		hidden[i] = true
+	}
+
+	kept := out[:0]
+	for i, e := range out {
+		if !hidden[i] {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+type budgetBar struct {
+	Bar
+	b        Bar
+	width    int
+	sepWidth int
+}
+
+// NewBudgetBar wraps b, fitting the elements within width columns
+// using Budget before they are written.
+func NewBudgetBar(b Bar, width, sepWidth int) Bar {
+	return &budgetBar{b: b, width: width, sepWidth: sepWidth}
+}
+
+// Write ...
+func (b *budgetBar) Write(v []Element) error {
+	return b.b.Write(Budget(v, b.width, b.sepWidth))
+}
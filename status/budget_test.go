@@ -0,0 +1,89 @@
+package status
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"æøå", 3},
+		{"日本", 4},
+		{"👍🏽", 2},
+		{"é", 1},
+	}
+
+	for _, test := range tests {
+		if got := TextWidth(test.s); got != test.want {
+			t.Errorf("TextWidth(%q) = %d, want %d", test.s, got, test.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 4, "hel…"},
+		{"hello", 1, "…"},
+		{"hello", 0, ""},
+		{"日本語", 5, "日本…"},
+		{"日本語", 4, "日…"},
+		{"ééé", 2, "é…"},
+		{"👍🏽👍🏽", 3, "👍🏽…"},
+	}
+
+	for _, test := range tests {
+		if got := Truncate(test.s, test.width); got != test.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", test.s, test.width, got, test.want)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	elems := []Element{
+		{FullText: "aaaaaaaa", ShortText: "aa", Priority: 2},
+		{FullText: "bbbbbbbb", Priority: 1},
+		{FullText: "cccccccc", ShortText: "c", Priority: 2},
+	}
+
+	tests := []struct {
+		name  string
+		width int
+		want  []string
+	}{
+		{"fits", 30, []string{"aaaaaaaa", "bbbbbbbb", "cccccccc"}},
+		{"short text of the last", 29, []string{"aaaaaaaa", "bbbbbbbb", "c"}},
+		{"short texts", 22, []string{"aa", "bbbbbbbb", "c"}},
+		{"truncate lowest priority", 16, []string{"aa", "bbbbbb…", "c"}},
+		{"truncate to the minimum", 13, []string{"aa", "bbb…", "c"}},
+		{"hide lowest priority", 12, []string{"aa", "c"}},
+		{"hide later first", 5, []string{"aa"}},
+		{"hide all", 0, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := []string{}
+			for _, e := range Budget(elems, test.width, 3) {
+				got = append(got, e.Text())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Budget(%d) = %q, want %q", test.width, got, test.want)
+			}
+		})
+	}
+
+	if elems[0].FullText != "aaaaaaaa" {
+		t.Error("Budget modified its argument")
+	}
+}
@@ -1,34 +1,222 @@
 package status
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 )
 
-// Color represents a rgb color
+// Color represents a rgb color with an alpha channel. The zero value
+// is opaque black, so that colors such as Color{R: 255} are opaque,
+// use RGBA for transparency.
 type Color struct {
 	R uint8
 	G uint8
 	B uint8
+
+	// transparency is 255 - alpha.
+	transparency uint8
 }
 
-// HexStr converts a color struct to a hex string repr.
-func (c Color) String() string {
+// RGB returns the opaque color with the given components.
+func RGB(r, g, b uint8) Color {
+	return Color{R: r, G: g, B: b}
+}
+
+// RGBA returns the color with the given components, an alpha
+// of 255 is opaque and 0 is fully transparent.
+func RGBA(r, g, b, a uint8) Color {
+	return Color{r, g, b, 255 - a}
+}
+
+// Alpha returns the alpha of c, 255 is opaque.
+func (c Color) Alpha() uint8 {
+	return 255 - c.transparency
+}
+
+// HSL returns the opaque color with the given hue in degrees,
+// and saturation and lightness between 0 and 1.
+func HSL(h, s, l float64) Color {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = clamp(s, 0, 1)
+	l = clamp(l, 0, 1)
+
+	// See https://www.w3.org/TR/css-color-3/#hsl-color
+	a := s * math.Min(l, 1-l)
+	f := func(n float64) uint8 {
+		k := math.Mod(n+h/30, 12)
+		v := l - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))
+		return uint8(math.Round(v * 255))
+	}
+	return RGB(f(0), f(8), f(4))
+}
+
+// HSLA is like HSL, with alpha between 0 and 1.
+func HSLA(h, s, l, alpha float64) Color {
+	c := HSL(h, s, l)
+	return RGBA(c.R, c.G, c.B, uint8(math.Round(clamp(alpha, 0, 1)*255)))
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(v, max))
+}
+
+// Opaque returns true if c has no transparency.
+func (c Color) Opaque() bool {
+	return c.transparency == 0
+}
+
+// Lerp interpolates linearly between c and to, t is clamped
+// between 0 (c) and 1 (to).
+func (c Color) Lerp(to Color, t float64) Color {
+	t = clamp(t, 0, 1)
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return RGBA(mix(c.R, to.R), mix(c.G, to.G), mix(c.B, to.B), mix(c.Alpha(), to.Alpha()))
+}
+
+// Hex returns the color as #rrggbb, ignoring alpha. It is used
+// by bars which do not support transparency.
+func (c Color) Hex() string {
 	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
 }
 
+// ARGB returns the color as #aarrggbb, as used by lemonbar and
+// polybar, or as #rrggbb if it is opaque.
+func (c Color) ARGB() string {
+	if c.Opaque() {
+		return c.Hex()
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.Alpha(), c.R, c.G, c.B)
+}
+
+// String returns the color as #rrggbbaa, as used by i3bar and CSS,
+// or as #rrggbb if it is opaque.
+func (c Color) String() string {
+	if c.Opaque() {
+		return c.Hex()
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.Alpha())
+}
+
 func (c Color) MarshalJSON() ([]byte, error) {
-	b := bytes.NewBufferString("\"")
-	b.WriteString(c.String())
-	b.WriteString("\"")
-	return b.Bytes(), nil
+	return json.Marshal(c.String())
+}
+
+func (c *Color) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid color: %s", data)
+	}
+	*c, err = ParseColor(s)
+	return
+}
+
+// ParseColor parses a color in one of the formats:
+//
+//	#rgb, #rgba, #rrggbb, #rrggbbaa
+//	CSS color names, eg. red or rebeccapurple, and transparent
+//	hsl(h, s%, l%) and hsla(h, s%, l%, a)
+func ParseColor(s string) (c Color, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if strings.HasPrefix(s, "#") {
+		return parseHex(s)
+	}
+	if strings.HasPrefix(s, "hsl") {
+		return parseHSL(s)
+	}
+	if c, ok := colorNames[s]; ok {
+		return c, nil
+	}
+	return Color{}, fmt.Errorf("%s is not a valid color", s)
+}
+
+func parseHex(hex string) (c Color, err error) {
+	digits := hex[1:]
+	switch len(digits) {
+	case 3, 4:
+		// Short form, each digit is repeated.
+		var long strings.Builder
+		for _, d := range digits {
+			long.WriteRune(d)
+			long.WriteRune(d)
+		}
+		digits = long.String()
+		break
+
+	case 6, 8:
+		break
+
+	default:
+		return Color{}, fmt.Errorf("%s is not a valid hex color: invalid length %d",
+			hex, len(hex))
+	}
+
+	v := [4]uint8{3: 255}
+	for i := 0; i < len(digits)/2; i++ {
+		n, err := strconv.ParseUint(digits[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return Color{}, fmt.Errorf("%s is not a valid hex color: %v", hex, err)
+		}
+		v[i] = uint8(n)
+	}
+	return RGBA(v[0], v[1], v[2], v[3]), nil
+}
+
+func parseHSL(s string) (c Color, err error) {
+	invalid := fmt.Errorf("%s is not a valid hsl color", s)
+
+	var args string
+	alpha := strings.HasPrefix(s, "hsla(")
+	if alpha {
+		args = strings.TrimPrefix(s, "hsla(")
+	} else {
+		args = strings.TrimPrefix(s, "hsl(")
+	}
+	if args == s || !strings.HasSuffix(args, ")") {
+		return Color{}, invalid
+	}
+	fields := strings.Split(strings.TrimSuffix(args, ")"), ",")
+	if (alpha && len(fields) != 4) || (!alpha && len(fields) != 3) {
+		return Color{}, invalid
+	}
+
+	var v [4]float64
+	for i, f := range fields {
+		f = strings.TrimSpace(f)
+		percent := strings.HasSuffix(f, "%")
+		// Saturation and lightness must be percentages.
+		if (i == 1 || i == 2) != percent {
+			return Color{}, invalid
+		}
+		v[i], err = strconv.ParseFloat(strings.TrimSuffix(f, "%"), 64)
+		if err != nil {
+			return Color{}, invalid
+		}
+		if percent {
+			v[i] /= 100
+		}
+	}
+
+	if !alpha {
+		return HSL(v[0], v[1], v[2]), nil
+	}
+	return HSLA(v[0], v[1], v[2], v[3]), nil
 }
 
-func (c *Color) UnmarshalJSON(data []byte) error {
-	s := string(data)
-	if len(s) != 9 {
-		return fmt.Errorf("invalid hex color: %s", s)
+// ColorFromHex is like ParseColor but panics if hex is invalid. It is
+// intended for colors known at compile time.
+func ColorFromHex(hex string) Color {
+	c, err := ParseColor(hex)
+	if err != nil {
+		panic(err)
 	}
-	*c = ColorFromHex(string(data)[1:8])
-	return nil
+	return c
 }
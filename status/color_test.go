@@ -0,0 +1,95 @@
+package status
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Color
+		ok   bool
+	}{
+		{"#FF0000", RGB(255, 0, 0), true},
+		{"#ff000080", RGBA(255, 0, 0, 128), true},
+		{"#f00", RGB(255, 0, 0), true},
+		{"#f008", RGBA(255, 0, 0, 136), true},
+		{" #ABCDEF ", RGB(0xAB, 0xCD, 0xEF), true},
+		{"red", RGB(255, 0, 0), true},
+		{"RebeccaPurple", RGB(0x66, 0x33, 0x99), true},
+		{"transparent", RGBA(0, 0, 0, 0), true},
+		{"hsl(0, 100%, 50%)", RGB(255, 0, 0), true},
+		{"hsl(120, 100%, 25%)", RGB(0, 128, 0), true},
+		{"hsl(-120, 100%, 50%)", RGB(0, 0, 255), true},
+		{"hsla(240, 100%, 50%, 0.5)", RGBA(0, 0, 255, 128), true},
+		{"hsl(0, 0%, 100%)", RGB(255, 255, 255), true},
+		{"#", Color{}, false},
+		{"#ff00", RGBA(255, 255, 0, 0), true},
+		{"#ff000", Color{}, false},
+		{"#gg0000", Color{}, false},
+		{"notacolor", Color{}, false},
+		{"hsl(0, 100, 50)", Color{}, false},
+		{"hsl(0, 100%, 50%", Color{}, false},
+		{"hsla(0, 100%, 50%)", Color{}, false},
+		{"hsl(0, 100%, 50%, 1)", Color{}, false},
+	}
+
+	for _, test := range tests {
+		got, err := ParseColor(test.s)
+		if (err == nil) != test.ok {
+			t.Errorf("ParseColor(%q): got error %v", test.s, err)
+			continue
+		}
+		if test.ok && got != test.want {
+			t.Errorf("ParseColor(%q) = %v, want %v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestColorFormats(t *testing.T) {
+	tests := []struct {
+		c              Color
+		hex, argb, str string
+	}{
+		{RGB(255, 0, 0), "#ff0000", "#ff0000", "#ff0000"},
+		{RGBA(255, 0, 0, 128), "#ff0000", "#80ff0000", "#ff000080"},
+		{RGBA(255, 0, 0, 0), "#ff0000", "#00ff0000", "#ff000000"},
+		// Literals without an alpha are opaque.
+		{Color{R: 255}, "#ff0000", "#ff0000", "#ff0000"},
+		{Color{}, "#000000", "#000000", "#000000"},
+	}
+
+	for _, test := range tests {
+		if got := test.c.Hex(); got != test.hex {
+			t.Errorf("Hex() = %s, want %s", got, test.hex)
+		}
+		if got := test.c.ARGB(); got != test.argb {
+			t.Errorf("ARGB() = %s, want %s", got, test.argb)
+		}
+		if got := test.c.String(); got != test.str {
+			t.Errorf("String() = %s, want %s", got, test.str)
+		}
+
+		data, err := json.Marshal(test.c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c Color
+		if err := json.Unmarshal(data, &c); err != nil || c != test.c {
+			t.Errorf("JSON round trip of %v: got %v, %v", test.c, c, err)
+		}
+	}
+}
+
+func TestColorNames(t *testing.T) {
+	for name, want := range colorNames {
+		got, err := ParseColor(name)
+		if err != nil || got != want {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v", name, got, err, want)
+		}
+		if name != "transparent" && !got.Opaque() {
+			t.Errorf("%s is not opaque", name)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package status
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ControlParams contains the parameters of a ControlRequest, which
+// are used depends on the method.
+type ControlParams struct {
+	Name    string   `json:"name,omitempty"`
+	Element *Element `json:"element,omitempty"`
+	// Seconds, 0 means forever for push and DefaultMessageTTL for message.
+	TTL float64 `json:"ttl,omitempty"`
+
+	// Used by click.
+	Instance string `json:"instance,omitempty"`
+	Button   int    `json:"button,omitempty"`
+}
+
+// ControlRequest is a single request sent over the control socket,
+// encoded as one line of JSON.
+//
+// Supported methods:
+//
+//	list     list the widgets and their current elements
+//	refresh  regenerate the widget(s) matching params.name
+//	hide     hide the widget(s) matching params.name
+//	show     show the widget(s) matching params.name
+//	message  display params.element for params.ttl seconds
+//	dismiss  remove the messages named params.name, or all messages
+//	push     push params.element to the push widgets for params.ttl seconds
+//	click    click params.button on the widget(s) matching params.name
+//	         and params.instance
+//	health   dump widget and goroutine health
+type ControlRequest struct {
+	ID     int           `json:"id"`
+	Method string        `json:"method"`
+	Params ControlParams `json:"params"`
+}
+
+// ControlResponse is the reply to a ControlRequest.
+type ControlResponse struct {
+	ID     int         `json:"id"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// WidgetInfo describes a widget in the reply to list and health.
+type WidgetInfo struct {
+	Index    int        `json:"index"`
+	Name     string     `json:"name,omitempty"`
+	Hidden   bool       `json:"hidden,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Elements []Element  `json:"elements,omitempty"`
+	Updates  int        `json:"updates,omitempty"`
+	Updated  *time.Time `json:"updated,omitempty"`
+}
+
+// Health is the reply to health.
+type Health struct {
+	Goroutines int          `json:"goroutines"`
+	Uptime     string       `json:"uptime"`
+	Widgets    []WidgetInfo `json:"widgets"`
+}
+
+// Pusher is implemented by generators whose elements are pushed
+// by external programs through the push control method.
+type Pusher interface {
+	Push(e Element, ttl time.Duration)
+}
+
+// ClickEvent describes a mouse click on an element, the fields
+// match the click events sent by i3bar.
+type ClickEvent struct {
+	Name     string `json:"name,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Button   int    `json:"button"`
+}
+
+// Clicker is implemented by generators which react to clicks on
+// their elements. The widget is refreshed after each click.
+type Clicker interface {
+	Click(ev ClickEvent)
+}
+
+// shellQuote quotes s as a single argument for sh.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// clickCommandLine returns the shell command line which runs cmd with
+// the button, name and instance of e as arguments. It is used by bars
+// which run a command when an element is clicked.
+func clickCommandLine(cmd string, e Element, button int) string {
+	return fmt.Sprintf("%s %d %s %s", cmd, button,
+		shellQuote(e.Name), shellQuote(e.Instance))
+}
+
+type ctlRequest struct {
+	req   ControlRequest
+	reply chan ControlResponse
+}
+
+type message struct {
+	e       Element
+	expires time.Time
+}
+
+// DefaultMessageTTL is how long a message is displayed
+// when no TTL is given.
+const DefaultMessageTTL = 10 * time.Second
+
+// SetControlSocket sets the path of the unix socket on which
+// control requests are accepted while the status is running.
+func (s *Status) SetControlSocket(path string) error {
+	if s.started {
+		return fmt.Errorf("cannot set control socket: %w", ErrStarted)
+	}
+	s.ctlpath = path
+	return nil
+}
+
+// Control sends req to the control socket at path and returns the reply.
+func Control(path string, req ControlRequest) (resp ControlResponse, err error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	err = json.NewEncoder(conn).Encode(req)
+	if err != nil {
+		return
+	}
+	err = json.NewDecoder(conn).Decode(&resp)
+	return
+}
+
+// listenControl starts accepting connections on the control socket,
+// the returned listener must be closed when the status stops.
+func (s *Status) listenControl() (l net.Listener, err error) {
+	// Remove a stale socket left behind by a previous instance.
+	if conn, err := net.Dial("unix", s.ctlpath); err != nil {
+		os.Remove(s.ctlpath)
+	} else {
+		conn.Close()
+	}
+
+	l, err = net.Listen("unix", s.ctlpath)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveControl(conn)
+		}
+	}()
+	return
+}
+
+// serveControl handles the requests of a single control connection, one
+// request per line, until the connection is closed.
+func (s *Status) serveControl(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for r.Scan() {
+		var req ControlRequest
+		err := json.Unmarshal(r.Bytes(), &req)
+		if err != nil {
+			enc.Encode(ControlResponse{Error: err.Error()})
+			continue
+		}
+
+		// The status loop no longer recieves requests once it stops.
+		c := ctlRequest{req, make(chan ControlResponse, 1)}
+		select {
+		case s.ctlch <- c:
+			break
+		case <-s.done:
+			enc.Encode(ControlResponse{ID: req.ID, Error: "status stopped"})
+			return
+		}
+		if err = enc.Encode(<-c.reply); err != nil {
+			return
+		}
+	}
+}
+
+// widgetInfo returns information about the widget with the given index.
+// The reply is encoded by the connection goroutine, so it must not share
+// any memory with the status loop.
+func (s *Status) widgetInfo(i int) WidgetInfo {
+	info := WidgetInfo{
+		Index:    i,
+		Name:     s.widgets[i].Name,
+		Hidden:   s.hidden[i],
+		Elements: append([]Element(nil), s.cache[i]...),
+		Updates:  s.updates[i],
+	}
+	if !s.updated[i].IsZero() {
+		updated := s.updated[i]
+		info.Updated = &updated
+	}
+	if err := s.widgets[i].Error; err != nil {
+		info.Error = err.Error()
+	}
+	return info
+}
+
+// setHidden hides or shows the widgets matching name.
+func (s *Status) setHidden(name string, hidden bool) (n int) {
+	for i := range s.widgets {
+		if s.matches(i, name) {
+			s.hidden[i] = hidden
+			n++
+		}
+	}
+	return
+}
+
+// handleControl handles a control request from the status loop and
+// returns the reply. The bar must be updated if redraw is true.
+func (s *Status) handleControl(req ControlRequest) (resp ControlResponse, redraw bool) {
+	resp.ID = req.ID
+
+	needName := func() bool {
+		if req.Params.Name == "" {
+			resp.Error = fmt.Sprintf("%s: missing name", req.Method)
+			return false
+		}
+		return true
+	}
+
+	matched := func(n int) {
+		if n == 0 {
+			resp.Error = fmt.Sprintf("no widget matching %s", req.Params.Name)
+			return
+		}
+		resp.Result = n
+	}
+
+	switch req.Method {
+	case "list":
+		infos := make([]WidgetInfo, len(s.widgets))
+		for i := range s.widgets {
+			infos[i] = s.widgetInfo(i)
+		}
+		resp.Result = infos
+
+	case "refresh":
+		if needName() {
+			matched(s.refreshByName(req.Params.Name))
+		}
+
+	case "hide", "show":
+		if needName() {
+			n := s.setHidden(req.Params.Name, req.Method == "hide")
+			matched(n)
+			redraw = n > 0
+		}
+
+	case "message":
+		if req.Params.Element == nil {
+			resp.Error = "message: missing element"
+			break
+		}
+		ttl := time.Duration(req.Params.TTL * float64(time.Second))
+		if ttl <= 0 {
+			ttl = DefaultMessageTTL
+		}
+		s.messages = append(s.messages, message{*req.Params.Element, time.Now().Add(ttl)})
+		s.resetMessageTimer()
+		resp.Result = len(s.messages)
+		redraw = true
+
+	case "dismiss":
+		kept := s.messages[:0]
+		for _, m := range s.messages {
+			if req.Params.Name != "" && m.e.Name != req.Params.Name {
+				kept = append(kept, m)
+			}
+		}
+		n := len(s.messages) - len(kept)
+		s.messages = kept
+		s.resetMessageTimer()
+		if n == 0 {
+			resp.Error = "dismiss: no matching messages"
+			break
+		}
+		resp.Result = n
+		redraw = true
+
+	case "push":
+		e := req.Params.Element
+		if e == nil || e.Name == "" {
+			resp.Error = "push: missing element name"
+			break
+		}
+		n := 0
+		ttl := time.Duration(req.Params.TTL * float64(time.Second))
+		for _, w := range s.widgets {
+			if p, ok := w.Gen.(Pusher); ok {
+				p.Push(*e, ttl)
+				n++
+			}
+		}
+		if n == 0 {
+			resp.Error = "push: there are no push widgets"
+			break
+		}
+		resp.Result = n
+
+	case "click":
+		if needName() {
+			ev := ClickEvent{Name: req.Params.Name,
+				Instance: req.Params.Instance, Button: req.Params.Button}
+			matched(s.click(ev))
+		}
+
+	case "health":
+		h := Health{
+			Goroutines: runtime.NumGoroutine(),
+			Uptime:     time.Since(s.startTime).Round(time.Second).String(),
+		}
+		for i := range s.widgets {
+			info := s.widgetInfo(i)
+			info.Elements = nil
+			h.Widgets = append(h.Widgets, info)
+		}
+		resp.Result = h
+
+	default:
+		resp.Error = fmt.Sprintf("unknown method: %s", req.Method)
+	}
+
+	if resp.Error != "" {
+		log.Printf("Control request failed: %s\n", resp.Error)
+	}
+	return
+}
+
+// click passes ev to the widgets matching its name and instance
+// and returns the number of widgets which matched.
+func (s *Status) click(ev ClickEvent) (n int) {
+	for i, w := range s.widgets {
+		if !s.matches(i, ev.Name) || (ev.Instance != "" && !s.matches(i, ev.Instance)) {
+			continue
+		}
+		n++
+		if c, ok := w.Gen.(Clicker); ok {
+			c.Click(ev)
+			s.refresh(i)
+		}
+	}
+	return
+}
+
+// expireMessages removes the expired messages and returns true
+// if any were removed.
+func (s *Status) expireMessages() bool {
+	now := time.Now()
+	kept := s.messages[:0]
+	for _, m := range s.messages {
+		if m.expires.After(now) {
+			kept = append(kept, m)
+		}
+	}
+	expired := len(kept) != len(s.messages)
+	s.messages = kept
+	s.resetMessageTimer()
+	return expired
+}
+
+// resetMessageTimer makes msgtimer fire when the next message expires.
+func (s *Status) resetMessageTimer() {
+	if s.msgtimer != nil {
+		s.msgtimer.Stop()
+		s.msgtimer = nil
+	}
+
+	var next time.Time
+	for _, m := range s.messages {
+		if next.IsZero() || m.expires.Before(next) {
+			next = m.expires
+		}
+	}
+	if !next.IsZero() {
+		s.msgtimer = time.NewTimer(time.Until(next))
+	}
+}
+
+// messageTimeout returns the channel of msgtimer, or nil if
+// no message is going to expire.
+func (s *Status) messageTimeout() <-chan time.Time {
+	if s.msgtimer == nil {
+		return nil
+	}
+	return s.msgtimer.C
+}
@@ -0,0 +1,96 @@
+package status
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHandleControl(t *testing.T) {
+	s, err := New(&recordBar{}, WithWidgets(
+		Widget{Name: "clock", Gen: testGen{}},
+		Widget{Name: "cpu", Gen: testGen{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.cache = [][]Element{{{Name: "clock", Instance: "Europe/Oslo"}}, nil}
+	s.hidden = make([]bool, 2)
+	s.updates = make([]int, 2)
+	s.updated = make([]time.Time, 2)
+	s.refreshch = []chan bool{make(chan bool, 1), make(chan bool, 1)}
+	defer func() {
+		if s.msgtimer != nil {
+			s.msgtimer.Stop()
+		}
+	}()
+
+	msg := func(name string) ControlParams {
+		return ControlParams{Element: &Element{Name: name, FullText: name}}
+	}
+
+	// The requests are handled in order on the same status.
+	tests := []struct {
+		method   string
+		params   ControlParams
+		result   interface{} // nil if not checked
+		failed   bool
+		redraw   bool
+		messages int
+	}{
+		{"hide", ControlParams{Name: "cpu"}, 1, false, true, 0},
+		{"show", ControlParams{Name: "Europe/Oslo"}, 1, false, true, 0},
+		{"hide", ControlParams{Name: "disk"}, nil, true, false, 0},
+		{"hide", ControlParams{}, nil, true, false, 0},
+		{"refresh", ControlParams{Name: "clock"}, 1, false, false, 0},
+		{"message", msg("a"), 1, false, true, 1},
+		{"message", msg("b"), 2, false, true, 2},
+		{"message", ControlParams{}, nil, true, false, 2},
+		{"dismiss", ControlParams{Name: "a"}, 1, false, true, 1},
+		{"dismiss", ControlParams{Name: "a"}, nil, true, false, 1},
+		{"message", msg("c"), 2, false, true, 2},
+		{"dismiss", ControlParams{}, 2, false, true, 0},
+		{"push", msg("p"), nil, true, false, 0},
+		{"unknown", ControlParams{}, nil, true, false, 0},
+	}
+
+	for i, test := range tests {
+		req := ControlRequest{ID: i, Method: test.method, Params: test.params}
+		resp, redraw := s.handleControl(req)
+		if resp.ID != i || (resp.Error != "") != test.failed || redraw != test.redraw {
+			t.Errorf("#%d %s: got %+v and redraw %v", i, test.method, resp, redraw)
+		}
+		if test.result != nil && resp.Result != test.result {
+			t.Errorf("#%d %s: got result %v, want %v", i, test.method, resp.Result, test.result)
+		}
+		if len(s.messages) != test.messages {
+			t.Errorf("#%d %s: got %d messages, want %d", i, test.method,
+				len(s.messages), test.messages)
+		}
+	}
+	if s.hidden[0] || !s.hidden[1] {
+		t.Errorf("got hidden %v", s.hidden)
+	}
+	if len(s.refreshch[0]) != 1 || len(s.refreshch[1]) != 0 {
+		t.Error("refresh did not refresh only the clock")
+	}
+}
+
+func TestMessageTTL(t *testing.T) {
+	s := NewStatus(&recordBar{})
+	defer func() { s.msgtimer.Stop() }()
+
+	for _, ttl := range []float64{0, 0.01} {
+		s.handleControl(ControlRequest{Method: "message",
+			Params: ControlParams{Element: &Element{FullText: "a"}, TTL: ttl}})
+	}
+	if s.expireMessages() || len(s.messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(s.messages))
+	}
+	if left := time.Until(s.messages[0].expires); left <= 0 || left > DefaultMessageTTL {
+		t.Errorf("message without a ttl expires in %v", left)
+	}
+
+	<-s.messageTimeout()
+	if !s.expireMessages() || len(s.messages) != 1 {
+		t.Errorf("got %d messages after the timeout, want 1", len(s.messages))
+	}
+}
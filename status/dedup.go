@@ -0,0 +1,38 @@
+package status
+
+import (
+	"bytes"
+)
+
+type dedupWriter struct {
+	out  BarWriter
+	cur  bytes.Buffer
+	prev []byte
+}
+
+// NewDedupWriter wraps out in a BarWriter which buffers everything
+// written until Flush and skips the write if it is byte-identical
+// to the previously flushed output.
+func NewDedupWriter(out BarWriter) BarWriter {
+	return &dedupWriter{out: out}
+}
+
+func (w *dedupWriter) Write(p []byte) (int, error) {
+	return w.cur.Write(p)
+}
+
+// Flush writes the buffered output unless it is unchanged.
+func (w *dedupWriter) Flush() (err error) {
+	defer w.cur.Reset()
+
+	if w.cur.Len() == 0 || bytes.Equal(w.cur.Bytes(), w.prev) {
+		return
+	}
+	w.prev = append(w.prev[:0], w.cur.Bytes()...)
+
+	_, err = w.out.Write(w.prev)
+	if err != nil {
+		return
+	}
+	return w.out.Flush()
+}
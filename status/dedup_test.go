@@ -0,0 +1,36 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestDedupWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		flushes []string // written and flushed in order
+		want    string
+	}{
+		{"identical", []string{"a\n", "a\n"}, "a\n"},
+		{"changed", []string{"a\n", "b\n"}, "a\nb\n"},
+		{"changed back", []string{"a\n", "b\n", "a\n"}, "a\nb\na\n"},
+		{"empty", []string{"", "a\n", ""}, "a\n"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		w := NewDedupWriter(bufio.NewWriter(&buf))
+		for _, s := range test.flushes {
+			if _, err := w.Write([]byte(s)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// DwmOptions configures the dwm output.
+type DwmOptions struct {
+	// Display is the X display to connect to, $DISPLAY if empty.
+	Display string
+
+	// Separator is written between elements, in SeparatorColor if
+	// set, eg. the color of RoleSeparator in the theme.
+	// The color requires Status2d.
+	Separator      string
+	SeparatorColor *Color
+
+	// Status2d enables the color codes of the dwm status2d patch.
+	Status2d bool
+}
+
+type dwm struct {
+	Bar
+	opts DwmOptions
+	conn *xgb.Conn
+	root xproto.Window
+	utf8 xproto.Atom
+}
+
+// NewDwm implements the Bar interface and supports dwm by setting
+// the name (WM_NAME) of the root window, like xsetroot -name. The
+// bar implements io.Closer, which closes the connection to X.
+func NewDwm(opts DwmOptions) (Bar, error) {
+	conn, err := xgb.NewConnDisplay(opts.Display)
+	if err != nil {
+		return nil, fmt.Errorf("dwm: unable to connect to X: %v", err)
+	}
+
+	name := "UTF8_STRING"
+	reply, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("dwm: unable to intern %s: %v", name, err)
+	}
+
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	return &dwm{opts: opts, conn: conn, root: root, utf8: reply.Atom}, nil
+}
+
+// Close closes the connection to X.
+func (b *dwm) Close() error {
+	b.conn.Close()
+	return nil
+}
+
+// status2dEscape replaces '^' which starts a status2d command and
+// cannot be escaped.
+var status2dEscape = strings.NewReplacer("^", "ˆ")
+
+// separator returns the separator, in its color with status2d.
+func (b *dwm) separator() string {
+	if !b.opts.Status2d || b.opts.SeparatorColor == nil {
+		return b.opts.Separator
+	}
+	return fmt.Sprintf("^c%s^%s^d^", b.opts.SeparatorColor.Hex(), b.opts.Separator)
+}
+
+// Write ...
+func (b *dwm) Write(v []Element) (err error) {
+	var s strings.Builder
+
+	sep := b.separator()
+	for i, e := range v {
+		if i > 0 {
+			s.WriteString(sep)
+		}
+
+		if !b.opts.Status2d {
+			s.WriteString(e.Text())
+			continue
+		}
+
+		if e.Color != nil {
+			fmt.Fprintf(&s, "^c%s^", e.Color.Hex())
+		}
+		if e.Background != nil {
+			fmt.Fprintf(&s, "^b%s^", e.Background.Hex())
+		}
+		if len(e.Spans) == 0 {
+			s.WriteString(status2dEscape.Replace(e.FullText))
+		}
+		for _, sp := range e.Spans {
+			if sp.Color != nil {
+				fmt.Fprintf(&s, "^c%s^", sp.Color.Hex())
+			}
+			if sp.Background != nil {
+				fmt.Fprintf(&s, "^b%s^", sp.Background.Hex())
+			}
+			s.WriteString(status2dEscape.Replace(sp.Text))
+			if sp.Color != nil || sp.Background != nil {
+				// Restore the colors of the element.
+				s.WriteString("^d^")
+				if e.Color != nil {
+					fmt.Fprintf(&s, "^c%s^", e.Color.Hex())
+				}
+				if e.Background != nil {
+					fmt.Fprintf(&s, "^b%s^", e.Background.Hex())
+				}
+			}
+		}
+		if e.Color != nil || e.Background != nil {
+			s.WriteString("^d^")
+		}
+	}
+
+	data := []byte(s.String())
+	return xproto.ChangePropertyChecked(b.conn, xproto.PropModeReplace, b.root,
+		xproto.AtomWmName, b.utf8, 8, uint32(len(data)), data).Check()
+}
@@ -0,0 +1,54 @@
+package status
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+// TestDwm needs an X server, eg. run it with xvfb-run.
+func TestDwm(t *testing.T) {
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set")
+	}
+
+	red := ColorFromHex("#FF0000")
+	tests := []struct {
+		opts DwmOptions
+		v    []Element
+		want string
+	}{
+		{DwmOptions{Separator: " | "},
+			[]Element{{FullText: "a"}, {FullText: "b", Color: &red}},
+			"a | b"},
+		{DwmOptions{Separator: " | ", Status2d: true},
+			[]Element{{FullText: "a^b"}, {FullText: "b", Color: &red}},
+			"aˆb | ^c#ff0000^b^d^"},
+	}
+
+	for _, test := range tests {
+		b, err := NewDwm(test.opts)
+		if err != nil {
+			t.Skipf("no X server: %v", err)
+		}
+		d := b.(*dwm)
+
+		if err := b.Write(test.v); err != nil {
+			t.Fatal(err)
+		}
+		reply, err := xproto.GetProperty(d.conn, false, d.root, xproto.AtomWmName,
+			d.utf8, 0, 1024).Reply()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(reply.Value); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+
+		if err := b.(io.Closer).Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
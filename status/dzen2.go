@@ -1,58 +1,231 @@
 package status
 
 import (
+	"fmt"
 	"strings"
 )
 
+// Dzen2Options configures the dzen2 output.
+type Dzen2Options struct {
+	// Width is the width of the text area of dzen2 (-tw) and CharWidth
+	// the width of a character of the (monospace) font, both in pixels.
+	// Both must be set to align elements to the center and right.
+	Width     int
+	CharWidth int
+
+	// Separator is written between elements, in SeparatorColor if
+	// set, eg. the color of RoleSeparator in the theme.
+	Separator      string
+	SeparatorColor *Color
+
+	// ClickCommand is run by dzen2 when an element is clicked, see
+	// PolybarOptions. Empty disables click areas.
+	ClickCommand string
+
+	// ProgressWidth and ProgressHeight is the size in pixels of the
+	// bar drawn after elements with a Percentage, 0 disables them.
+	ProgressWidth  int
+	ProgressHeight int
+}
+
 type dzen2 struct {
 	Bar
-	out BarWriter
+	opts Dzen2Options
+	out  BarWriter
 }
 
 // NewDzen2Bar implements the Bar interface and supports
 // generating output for dzen2.
 func NewDzen2Bar(out BarWriter) Bar {
-	return &dzen2{out: out}
+	return NewDzen2(Dzen2Options{}, out)
+}
+
+// NewDzen2 is like NewDzen2Bar, but configured by opts.
+func NewDzen2(opts Dzen2Options, out BarWriter) Bar {
+	return &dzen2{opts: opts, out: out}
+}
+
+func dzen2Escape(s string) string {
+	return strings.Replace(s, "^", "^^", -1)
+}
+
+// dzen2Spans formats the spans of e, restoring the colors of the
+// element after each span. Bold, italic and underline are not supported,
+// and fonts containing ')' are ignored since it would end ^fn().
+func dzen2Spans(e Element) []byte {
+	reset := func(c *Color, command string) string {
+		if c != nil {
+			return fmt.Sprintf("^%s(%s)", command, c.Hex())
+		}
+		return fmt.Sprintf("^%s()", command)
+	}
+
+	var b strings.Builder
+	for _, sp := range e.Spans {
+		font := sp.Font != "" && !strings.Contains(sp.Font, ")")
+		if sp.Color != nil {
+			fmt.Fprintf(&b, "^fg(%s)", sp.Color.Hex())
+		}
+		if sp.Background != nil {
+			fmt.Fprintf(&b, "^bg(%s)", sp.Background.Hex())
+		}
+		if font {
+			fmt.Fprintf(&b, "^fn(%s)", sp.Font)
+		}
+
+		b.WriteString(dzen2Escape(sp.Text))
+
+		if font {
+			b.WriteString("^fn()")
+		}
+		if sp.Background != nil {
+			b.WriteString(reset(e.Background, "bg"))
+		}
+		if sp.Color != nil {
+			b.WriteString(reset(e.Color, "fg"))
+		}
+	}
+	return []byte(b.String())
+}
+
+// progressWidth returns the width in pixels of the progress bar
+// of e, including the space before it.
+func (b *dzen2) progressWidth(e Element) int {
+	if e.Percentage == nil || b.opts.ProgressWidth <= 0 {
+		return 0
+	}
+	return b.opts.CharWidth + b.opts.ProgressWidth
+}
+
+// element returns e formatted for dzen2 and its width in pixels.
+func (b *dzen2) element(e Element) (string, int) {
+	var s strings.Builder
+
+	// The command of a click area ends at the first ')',
+	// which cannot be escaped.
+	clickable := b.opts.ClickCommand != "" && e.Name != "" &&
+		!strings.Contains(clickCommandLine(b.opts.ClickCommand, e, 1), ")")
+	if clickable {
+		for button := 1; button <= 3; button++ {
+			fmt.Fprintf(&s, "^ca(%d, %s)", button,
+				clickCommandLine(b.opts.ClickCommand, e, button))
+		}
+	}
+
+	// Colors.
+	if e.Color != nil {
+		fmt.Fprintf(&s, "^fg(%s)", e.Color.Hex())
+	}
+	if e.Background != nil {
+		fmt.Fprintf(&s, "^bg(%s)", e.Background.Hex())
+	}
+
+	// Contents.
+	if len(e.Spans) > 0 {
+		s.Write(dzen2Spans(e))
+	} else {
+		s.WriteString(dzen2Escape(e.FullText))
+	}
+	width := TextWidth(e.Text()) * b.opts.CharWidth
+
+	// Progress bar, filled up to the percentage.
+	if progress := b.progressWidth(e); progress > 0 {
+		p := *e.Percentage
+		if p < 0 {
+			p = 0
+		} else if p > 100 {
+			p = 100
+		}
+		filled := b.opts.ProgressWidth * p / 100
+		fmt.Fprintf(&s, " ^r(%dx%d)^ro(%dx%d)", filled, b.opts.ProgressHeight,
+			b.opts.ProgressWidth-filled, b.opts.ProgressHeight)
+		width += progress
+	}
+
+	if e.Color != nil {
+		s.WriteString("^fg()")
+	}
+	if e.Background != nil {
+		s.WriteString("^bg()")
+	}
+	if clickable {
+		s.WriteString("^ca()^ca()^ca()")
+	}
+	return s.String(), width
+}
+
+// separator returns the separator in its color.
+func (b *dzen2) separator() string {
+	if b.opts.SeparatorColor == nil {
+		return b.opts.Separator
+	}
+	return fmt.Sprintf("^fg(%s)%s^fg()", b.opts.SeparatorColor.Hex(), b.opts.Separator)
 }
 
 // Write ...
 func (b *dzen2) Write(v []Element) (err error) {
-	// TODO: Support other formatting options.
-	bytes := make([]byte, 0)
-
-	format := func(command, data []byte) (err error) {
-		bytes = append(bytes, '^')
-		bytes = append(bytes, command...)
-		bytes = append(bytes, '(')
-		bytes = append(bytes, data...)
-		bytes = append(bytes, ')')
-		_, err = b.out.Write(bytes)
-		return
+	if b.opts.Width > 0 && b.opts.CharWidth > 0 {
+		// The progress bars in columns, rounded up.
+		progress := func(e Element) int {
+			return (b.progressWidth(e) + b.opts.CharWidth - 1) / b.opts.CharWidth
+		}
+		v = budget(v, b.opts.Width/b.opts.CharWidth, TextWidth(b.opts.Separator), progress)
+	}
+
+	type side struct {
+		texts []string
+		width int
+	}
+	sides := map[AlignStr]*side{
+		AlignLeft:   {},
+		AlignCenter: {},
+		AlignRight:  {},
 	}
 
+	sepWidth := TextWidth(b.opts.Separator) * b.opts.CharWidth
 	for _, e := range v {
-		// TODO: Fix alignment support.
-
-		// Colors.
-		if e.Color != nil {
-			err = format([]byte("fg"), []byte(e.Color.String()))
-			if err != nil {
-				return
-			}
-		}
-		if e.Background != nil {
-			err = format([]byte("bg"), []byte(e.Color.String()))
-			if err != nil {
-				return
-			}
-		}
-
-		// Contents.
-		str := strings.Replace(e.FullText, "^", "^^", -1)
-		bytes = append(bytes, []byte(str)...)
-	}
-	bytes = append(bytes, '\n')
-	_, err = b.out.Write(bytes)
-	b.out.Flush()
-	return
+		sd, ok := sides[e.Alignment]
+		if !ok {
+			sd = sides[AlignLeft]
+		}
+		text, width := b.element(e)
+		if len(sd.texts) > 0 {
+			sd.width += sepWidth
+		}
+		sd.texts = append(sd.texts, text)
+		sd.width += width
+	}
+
+	// Without knowing the widths everything is left aligned.
+	positioned := b.opts.Width > 0 && b.opts.CharWidth > 0
+
+	sep := b.separator()
+	var s strings.Builder
+	for _, align := range []AlignStr{AlignLeft, AlignCenter, AlignRight} {
+		sd := sides[align]
+		if len(sd.texts) == 0 {
+			continue
+		}
+
+		x := 0
+		if align == AlignCenter {
+			x = (b.opts.Width - sd.width) / 2
+		} else if align == AlignRight {
+			x = b.opts.Width - sd.width
+		}
+
+		if positioned && align != AlignLeft && x >= 0 {
+			fmt.Fprintf(&s, "^pa(%d)", x)
+		} else if s.Len() > 0 {
+			s.WriteString(sep)
+		}
+		s.WriteString(strings.Join(sd.texts, sep))
+	}
+	s.WriteByte('\n')
+
+	_, err = b.out.Write([]byte(s.String()))
+	if err != nil {
+		return
+	}
+	return b.out.Flush()
 }
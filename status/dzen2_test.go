@@ -0,0 +1,95 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestDzen2Click(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		e    Element
+		want string
+	}{
+		{"c", Element{Name: "n", FullText: "a"},
+			"^ca(1, c 1 'n' '')^ca(2, c 2 'n' '')^ca(3, c 3 'n' '')a^ca()^ca()^ca()"},
+		{"c", Element{FullText: "a"}, "a"},
+		{"", Element{Name: "n", FullText: "a"}, "a"},
+		{"c", Element{Name: "n)", FullText: "a"}, "a"},
+		{"c", Element{Name: "n", Instance: "(i)", FullText: "a"}, "a"},
+		{"c)", Element{Name: "n", FullText: "a"}, "a"},
+	}
+
+	for _, test := range tests {
+		b := &dzen2{opts: Dzen2Options{ClickCommand: test.cmd}}
+		if got, _ := b.element(test.e); got != test.want {
+			t.Errorf("element(%+v) with %q = %q, want %q", test.e, test.cmd, got, test.want)
+		}
+	}
+}
+
+func TestDzen2Align(t *testing.T) {
+	half := 50
+	tests := []struct {
+		name string
+		v    []Element
+		want string
+	}{
+		{"right", []Element{{FullText: "1234567", Alignment: AlignRight},
+			{FullText: "1234567", Alignment: AlignRight}},
+			"^pa(30)1234567 | 1234567\n"},
+		{"progress", []Element{{FullText: "abc", Alignment: AlignRight, Percentage: &half}},
+			"^pa(130)abc ^r(15x8)^ro(15x8)\n"},
+		// Truncated to fit the progress bars, 50+40+30+40+40 pixels.
+		{"truncated progress", []Element{
+			{FullText: "1234567", Alignment: AlignRight, Percentage: &half},
+			{FullText: "1234567", Alignment: AlignRight, Percentage: &half}},
+			"^pa(0)1234… ^r(15x8)^ro(15x8) | 123… ^r(15x8)^ro(15x8)\n"},
+		{"center", []Element{{FullText: "abcd", Alignment: AlignCenter, Percentage: &half}},
+			"^pa(60)abcd ^r(15x8)^ro(15x8)\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			out := bufio.NewWriter(&buf)
+			b := NewDzen2(Dzen2Options{Width: 200, CharWidth: 10, Separator: " | ",
+				ProgressWidth: 30, ProgressHeight: 8}, out)
+			if err := b.Write(test.v); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDzen2Spans(t *testing.T) {
+	red, blue := RGB(255, 0, 0), RGB(0, 0, 255)
+
+	tests := []struct {
+		name  string
+		color *Color // of the element
+		sp    Span
+		want  string
+	}{
+		{"plain", nil, Span{Text: "x"}, "x"},
+		{"escaped", nil, Span{Text: "a^b ^fg(red)"}, "a^^b ^^fg(red)"},
+		{"color", &red, Span{Text: "x", Color: &blue}, "^fg(#0000ff)x^fg(#ff0000)"},
+		{"default color", nil, Span{Text: "x", Color: &blue}, "^fg(#0000ff)x^fg()"},
+		{"background", &red, Span{Text: "x", Background: &blue}, "^bg(#0000ff)x^bg()"},
+		{"font", nil, Span{Text: "x", Font: "Sans-10"}, "^fn(Sans-10)x^fn()"},
+		{"font paren", nil, Span{Text: "x", Font: "a)^fg(red)"}, "x"},
+		{"all", &red, Span{Text: "^", Color: &blue, Font: "Sans"},
+			"^fg(#0000ff)^fn(Sans)^^^fn()^fg(#ff0000)"},
+	}
+
+	for _, test := range tests {
+		e := Element{Color: test.color, Spans: []Span{test.sp}}
+		if got := string(dzen2Spans(e)); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
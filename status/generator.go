@@ -0,0 +1,145 @@
+package status
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Sink is handed to a running ContextGenerator and is used to publish
+// its output and to recieve refresh requests.
+type Sink struct {
+	Widget *Widget
+	Index  int
+
+	// Refresh recieves a value when the widget should
+	// regenerate immediately.
+	Refresh chan bool
+
+	mailbox *Mailbox
+}
+
+// Put publishes e as the latest output of the widget, never blocks.
+func (s Sink) Put(e []Element) {
+	s.mailbox.Put(s.Index, e)
+}
+
+// RunEvery calls gen() once and then on every tick or refresh request,
+// publishing the output to sink, until ctx is cancelled or tick is closed.
+// The first error returned by gen() is returned.
+func RunEvery(ctx context.Context, sink Sink, tick <-chan time.Time,
+	gen func() ([]Element, error)) error {
+
+	for {
+		prod, err := gen()
+		if err != nil {
+			return err
+		}
+		sink.Put(prod)
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case _, ok := <-tick:
+			if !ok {
+				return nil
+			}
+			break
+
+		case <-sink.Refresh:
+			break
+		}
+	}
+}
+
+type legacy struct {
+	g Generator
+}
+
+// Legacy adapts a Generator to the ContextGenerator interface. Each
+// adapted generator is given its own stop and done channels. Widgets
+// are adapted by the status, this is used by decorators like Marquee.
+func Legacy(g Generator) ContextGenerator {
+	return legacy{g}
+}
+
+// contextGenerator returns g as a ContextGenerator, adapting it
+// with Legacy if it is a Generator.
+func contextGenerator(g interface{}) (ContextGenerator, bool) {
+	switch g := g.(type) {
+	case ContextGenerator:
+		return g, true
+	case Generator:
+		return Legacy(g), true
+	}
+	return nil, false
+}
+
+// Run implements ContextGenerator.
+func (l legacy) Run(ctx context.Context, sink Sink) error {
+	// Generatorfunc indexes Refresh by the widget index.
+	refresh := make([]chan bool, sink.Index+1)
+	refresh[sink.Index] = sink.Refresh
+
+	// Done and Errorch are buffered since some generators send
+	// done more than once, or both fail and stop on errors.
+	gctx := GeneratorCtx{
+		Mailbox: sink.mailbox,
+		Stop:    make(chan bool, 1),
+		Done:    make(chan bool, 2),
+		Errorch: make(chan WidgetError, 2),
+		Refresh: refresh,
+	}
+	go l.g.Generate(sink.Widget, sink.Index, &gctx)
+
+	select {
+	case <-ctx.Done():
+		gctx.Stop <- true
+		<-gctx.Done
+		return nil
+
+	case werror := <-gctx.Errorch:
+		return werror.Error
+
+	case <-gctx.Done:
+		// A generator may signal done right before its error.
+		select {
+		case werror := <-gctx.Errorch:
+			return werror.Error
+		default:
+			return nil
+		}
+	}
+}
+
+// group runs a set of goroutines and waits for all of them to return.
+// Unlike errgroup, an error does not cancel the others.
+type group struct {
+	wg sync.WaitGroup
+}
+
+func (g *group) Go(f func()) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		f()
+	}()
+}
+
+// WaitTimeout waits for all goroutines to return, but no longer than d.
+// Returns false if the deadline was exceeded.
+func (g *group) WaitTimeout(d time.Duration) bool {
+	done := make(chan bool)
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
@@ -0,0 +1,160 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// oklab is a color in the Oklab color space, where the distance
+// between two colors matches how different they look.
+// See https://bottosson.github.io/posts/oklab/
+type oklab struct {
+	L, A, B float64
+}
+
+func toLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func fromLinear(v float64) uint8 {
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(clamp(v, 0, 1) * 255))
+}
+
+func (c Color) oklab() oklab {
+	r, g, b := toLinear(c.R), toLinear(c.G), toLinear(c.B)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return oklab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+func (o oklab) color(alpha uint8) Color {
+	l := o.L + 0.3963377774*o.A + 0.2158037573*o.B
+	m := o.L - 0.1055613458*o.A - 0.0638541728*o.B
+	s := o.L - 0.0894841775*o.A - 1.2914855480*o.B
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return Color{
+		R:            fromLinear(4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		G:            fromLinear(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		B:            fromLinear(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s),
+		transparency: 255 - alpha,
+	}
+}
+
+// Blend is like Lerp but interpolates in the Oklab color space, which
+// avoids the muddy colors half way between eg. red and green.
+func (c Color) Blend(to Color, t float64) Color {
+	t = clamp(t, 0, 1)
+	a, b := c.oklab(), to.oklab()
+	mixed := oklab{
+		L: a.L + (b.L-a.L)*t,
+		A: a.A + (b.A-a.A)*t,
+		B: a.B + (b.B-a.B)*t,
+	}
+	alpha := uint8(math.Round(float64(c.Alpha()) + (float64(to.Alpha())-float64(c.Alpha()))*t))
+	return mixed.color(alpha)
+}
+
+// Stop is a color at a position of a Gradient, At is between 0 and 1.
+type Stop struct {
+	At    float64 `json:"at"`
+	Color Color   `json:"color"`
+}
+
+// Gradient is a sequence of colors sorted by position, the colors
+// in between are blended, see Blend.
+type Gradient []Stop
+
+// NewGradient returns a gradient of the colors spaced evenly.
+func NewGradient(colors ...Color) Gradient {
+	g := make(Gradient, len(colors))
+	for i, c := range colors {
+		g[i] = Stop{Color: c}
+		if len(colors) > 1 {
+			g[i].At = float64(i) / float64(len(colors)-1)
+		}
+	}
+	return g
+}
+
+// At returns the color at t, which is clamped between 0 and 1.
+func (g Gradient) At(t float64) Color {
+	if len(g) == 0 {
+		return Color{}
+	}
+	if t <= g[0].At {
+		return g[0].Color
+	}
+	for i := 1; i < len(g); i++ {
+		if t <= g[i].At {
+			from, to := g[i-1], g[i]
+			return from.Color.Blend(to.Color, (t-from.At)/(to.At-from.At))
+		}
+	}
+	return g[len(g)-1].Color
+}
+
+// Percent returns the color at the given percentage.
+func (g Gradient) Percent(p int) *Color {
+	c := g.At(float64(p) / 100)
+	return &c
+}
+
+// UnmarshalJSON accepts either a list of colors which are spaced evenly,
+// eg. ["red", "yellow", "green"], or a list of stops, eg.
+// [{"at": 0, "color": "red"}, {"at": 0.2, "color": "yellow"}, ...].
+func (g *Gradient) UnmarshalJSON(data []byte) error {
+	var colors []Color
+	if err := json.Unmarshal(data, &colors); err == nil {
+		*g = NewGradient(colors...)
+		return nil
+	}
+
+	var stops []Stop
+	if err := json.Unmarshal(data, &stops); err != nil {
+		return fmt.Errorf("invalid gradient: %v", err)
+	}
+	sort.SliceStable(stops, func(i, j int) bool { return stops[i].At < stops[j].At })
+	*g = stops
+	return nil
+}
+
+// HBarSpans is like HBarText, but each filled cell is colored by the
+// gradient at its position, so the bar changes color as it fills up.
+func HBarSpans(progress, size int, full, empty string, g Gradient) []Span {
+	if progress < 0 {
+		progress = 0
+	}
+	spans := make([]Span, 0, size)
+	for i := 0; i < progress && i < size; i++ {
+		var c *Color
+		if size > 1 {
+			c = g.Percent(i * 100 / (size - 1))
+		} else {
+			c = g.Percent(100)
+		}
+		spans = append(spans, Span{Text: full, Color: c})
+	}
+	if progress < size {
+		spans = append(spans, Span{Text: HBarText(0, size-progress, full, empty)})
+	}
+	return spans
+}
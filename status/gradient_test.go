@@ -0,0 +1,128 @@
+package status
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOklabRoundTrip(t *testing.T) {
+	colors := []Color{
+		RGB(0, 0, 0), RGB(255, 255, 255), RGB(255, 0, 0), RGB(0, 255, 0),
+		RGB(0, 0, 255), RGB(0x66, 0x33, 0x99), RGB(1, 2, 3),
+	}
+	for _, c := range colors {
+		if got := c.oklab().color(c.Alpha()); got != c {
+			t.Errorf("%v: got %v after converting to Oklab and back", c, got)
+		}
+	}
+}
+
+func TestBlend(t *testing.T) {
+	black, white := RGB(0, 0, 0), RGB(255, 255, 255)
+	red, green := RGB(255, 0, 0), RGB(0, 255, 0)
+
+	tests := []struct {
+		from, to Color
+		t        float64
+		want     Color
+	}{
+		{black, white, 0, black},
+		{black, white, 1, white},
+		{black, white, -1, black},
+		{black, white, 2, white},
+		// Perceptual middle grey, rather than 128.
+		{black, white, 0.5, RGB(99, 99, 99)},
+		// Not the dark olive of the linear blend.
+		{red, green, 0.5, RGB(208, 168, 0)},
+		{RGBA(0, 0, 0, 0), black, 0.5, RGBA(0, 0, 0, 128)},
+	}
+
+	for _, test := range tests {
+		if got := test.from.Blend(test.to, test.t); got != test.want {
+			t.Errorf("%v.Blend(%v, %v) = %v, want %v",
+				test.from, test.to, test.t, got, test.want)
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	red, yellow, green := RGB(255, 0, 0), RGB(255, 255, 0), RGB(0, 128, 0)
+
+	var stops Gradient
+	err := json.Unmarshal([]byte(`[{"at": 1, "color": "green"},
+		{"at": 0, "color": "red"}, {"at": 0.2, "color": "yellow"}]`), &stops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var even Gradient
+	if err := json.Unmarshal([]byte(`["red", "yellow", "green"]`), &even); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		g    Gradient
+		t    float64
+		want Color
+	}{
+		{"empty", nil, 0.5, Color{}},
+		{"single", NewGradient(red), 0.7, red},
+		{"start", even, 0, red},
+		{"below", even, -1, red},
+		{"stop", even, 0.5, yellow},
+		{"end", even, 1, green},
+		{"above", even, 2, green},
+		{"between", even, 0.25, red.Blend(yellow, 0.5)},
+		{"sorted stops", stops, 0.2, yellow},
+		{"uneven stops", stops, 0.6, yellow.Blend(green, 0.5)},
+	}
+
+	for _, test := range tests {
+		if got := test.g.At(test.t); got != test.want {
+			t.Errorf("%s: At(%v) = %v, want %v", test.name, test.t, got, test.want)
+		}
+	}
+
+	if got := *even.Percent(50); got != yellow {
+		t.Errorf("Percent(50) = %v, want %v", got, yellow)
+	}
+	if err := json.Unmarshal([]byte(`[1, 2]`), &even); err == nil {
+		t.Error("invalid gradient was accepted")
+	}
+}
+
+func TestHBarSpans(t *testing.T) {
+	g := NewGradient(RGB(255, 0, 0), RGB(0, 0, 255))
+
+	tests := []struct {
+		progress, size int
+		text           string
+		colored        int
+	}{
+		{0, 4, "----", 0},
+		{2, 4, "##--", 2},
+		{4, 4, "####", 4},
+		{6, 4, "####", 4},
+		{-1, 4, "----", 0},
+	}
+
+	for _, test := range tests {
+		spans := HBarSpans(test.progress, test.size, "#", "-", g)
+		text, colored := "", 0
+		for _, sp := range spans {
+			text += sp.Text
+			if sp.Color != nil {
+				colored++
+			}
+		}
+		if text != test.text || colored != test.colored {
+			t.Errorf("HBarSpans(%d, %d) = %q with %d colored, want %q with %d",
+				test.progress, test.size, text, colored, test.text, test.colored)
+		}
+	}
+
+	spans := HBarSpans(4, 4, "#", "-", g)
+	if *spans[0].Color != g.At(0) || *spans[3].Color != g.At(1) {
+		t.Errorf("the bar does not span the gradient: %v", spans)
+	}
+}
@@ -1,7 +1,11 @@
 package status
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
 )
 
 // Header is the json header used for i3-bar output
@@ -28,7 +32,7 @@ func NewI3Bar(header I3BarHeader, out BarWriter) Bar {
 func (w *i3Bar) writeHeader() (n int, err error) {
 	bytes := make([]byte, 0)
 
-	header, err := json.Marshal(I3BarHeader{Version: 1})
+	header, err := json.Marshal(w.header)
 	if err != nil {
 		return
 	}
@@ -38,12 +42,53 @@ func (w *i3Bar) writeHeader() (n int, err error) {
 	return w.out.Write(bytes)
 }
 
+// i3barElements replaces the spans of the elements with pango markup,
+// which is supported by i3bar (and swaybar), and clears the fields
+// which i3bar does not know about.
+func i3barElements(v []Element) []Element {
+	out := make([]Element, len(v))
+	for i, e := range v {
+		if len(e.Spans) > 0 {
+			e.FullText = PangoSpans(e.Spans)
+			e.Markup = "pango"
+			e.Spans = nil
+		}
+		e.Tooltip = ""
+		e.Percentage = nil
+		e.Alt = ""
+		e.Priority = 0
+		out[i] = e
+	}
+	return out
+}
+
+// ReadI3BarClicks reads the click events which i3bar writes to the
+// stdin of the status when ClickEvents is set in the header, and
+// sends them to c, see SetClickEvents. Returns when r is closed.
+func ReadI3BarClicks(r io.Reader, c chan<- ClickEvent) error {
+	// The events are an endless JSON array with one event per line,
+	// each but the first one prefixed by a comma.
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), ",")
+		if line == "" || line == "[" {
+			continue
+		}
+		var ev ClickEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			return fmt.Errorf("invalid click event: %s", line)
+		}
+		c <- ev
+	}
+	return scanner.Err()
+}
+
 func (w *i3Bar) Write(v []Element) (err error) {
 	bytes := make([]byte, 0)
 
-	data, err := json.Marshal(v)
+	data, err := json.Marshal(i3barElements(v))
 	if err != nil {
-		panic(err)
+		return
 	}
 	if !w.wroteHeader {
 		_, err = w.writeHeader()
@@ -57,6 +102,8 @@ func (w *i3Bar) Write(v []Element) (err error) {
 	bytes = append(bytes, '\n')
 
 	_, err = w.out.Write(bytes)
-	w.out.Flush()
-	return
+	if err != nil {
+		return
+	}
+	return w.out.Flush()
 }
@@ -0,0 +1,57 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadI3BarClicks(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []ClickEvent
+		ok   bool
+	}{
+		{"empty", "", nil, true},
+		{"no events", "[\n", nil, true},
+		{"events", "[\n" +
+			`{"name":"Clock","instance":"Europe/Oslo","button":1,"x":10,"y":2}` + "\n" +
+			`,{"name":"cpu","button":3}` + "\n",
+			[]ClickEvent{{"Clock", "Europe/Oslo", 1}, {"cpu", "", 3}}, true},
+		{"invalid", "[\n{\"name\":\n", nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := make(chan ClickEvent, 10)
+			err := ReadI3BarClicks(strings.NewReader(test.in), c)
+			if (err == nil) != test.ok {
+				t.Errorf("got error %v", err)
+			}
+			close(c)
+			var got []ClickEvent
+			for ev := range c {
+				got = append(got, ev)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestI3BarHeader(t *testing.T) {
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	b := NewI3Bar(I3BarHeader{Version: 1, ClickEvents: true}, out)
+	if err := b.Write(nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\"version\":1,\"click_events\":true}\n[\n[]\n,[]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package status
+
+import (
+	"fmt"
+	"sort"
+)
+
+// IconSet maps the semantic names of icons (eg. battery-full) to
+// the glyphs of a font.
+type IconSet map[string]string
+
+// Icon returns the glyph of the named icon, falling back to the
+// ascii set if s does not have it.
+func (s IconSet) Icon(name string) string {
+	if icon, ok := s[name]; ok {
+		return icon
+	}
+	return IconSets["ascii"][name]
+}
+
+// With returns a copy of s with the icons of o added, replacing
+// the icons of s with the same names.
+func (s IconSet) With(o IconSet) IconSet {
+	set := make(IconSet, len(s)+len(o))
+	for name, icon := range s {
+		set[name] = icon
+	}
+	for name, icon := range o {
+		set[name] = icon
+	}
+	return set
+}
+
+// IconSets contains the bundled icon sets by name. The icons are:
+//
+//	battery-empty, battery-quarter, battery-half,
+//	battery-three-quarters, battery-full, battery-charging
+//	bar-full, bar-empty (the cells of progress bars)
+var IconSets = map[string]IconSet{
+	"fontawesome": {
+		"battery-empty":          "\uf244",
+		"battery-quarter":        "\uf243",
+		"battery-half":           "\uf242",
+		"battery-three-quarters": "\uf241",
+		"battery-full":           "\uf240",
+		"battery-charging":       "\uf0e7",
+		"bar-full":               "+",
+		"bar-empty":              "-",
+	},
+	"nerdfont": {
+		"battery-empty":          "\U000f008e",
+		"battery-quarter":        "\U000f007b",
+		"battery-half":           "\U000f007e",
+		"battery-three-quarters": "\U000f0081",
+		"battery-full":           "\U000f0079",
+		"battery-charging":       "\U000f0241",
+		"bar-full":               "█",
+		"bar-empty":              "░",
+	},
+	"emoji": {
+		"battery-empty":          "🪫",
+		"battery-quarter":        "🪫",
+		"battery-half":           "🔋",
+		"battery-three-quarters": "🔋",
+		"battery-full":           "🔋",
+		"battery-charging":       "⚡",
+		"bar-full":               "▰",
+		"bar-empty":              "▱",
+	},
+	"ascii": {
+		"battery-empty":          "[    ]",
+		"battery-quarter":        "[=   ]",
+		"battery-half":           "[==  ]",
+		"battery-three-quarters": "[=== ]",
+		"battery-full":           "[====]",
+		"battery-charging":       "+",
+		"bar-full":               "#",
+		"bar-empty":              "-",
+	},
+}
+
+// DefaultIconSet is used unless another icon set is configured.
+var DefaultIconSet = IconSets["fontawesome"]
+
+// IconSetNames returns the names of the bundled icon sets, sorted.
+func IconSetNames() (names []string) {
+	for name := range IconSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// LookupIconSet returns the bundled icon set with the given name.
+func LookupIconSet(name string) (IconSet, error) {
+	s, ok := IconSets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown icon set: %s", name)
+	}
+	return s, nil
+}
@@ -2,6 +2,7 @@ package status
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +17,62 @@ func NewLemonbar(out BarWriter) Bar {
 	return &lemonbar{out: out}
 }
 
+func lemonbarEscape(s string) string {
+	return strings.Replace(s, "%", "%%", -1)
+}
+
+// lemonbarFont returns true if font is the index of a font,
+// anything else would end up in the markup.
+func lemonbarFont(font string) bool {
+	n, err := strconv.Atoi(font)
+	return err == nil && n > 0
+}
+
+// lemonbarSpans formats the spans of e, restoring the colors of
+// the element after each span. Bold and italic are not supported,
+// and fonts must be given by index.
+func lemonbarSpans(e Element) []byte {
+	reset := func(c *Color, prefix string) string {
+		if c != nil {
+			return fmt.Sprintf("%%{%s%s}", prefix, c.ARGB())
+		}
+		return fmt.Sprintf("%%{%s-}", prefix)
+	}
+
+	var b strings.Builder
+	for _, sp := range e.Spans {
+		font := lemonbarFont(sp.Font)
+		if sp.Color != nil {
+			fmt.Fprintf(&b, "%%{F%s}", sp.Color.ARGB())
+		}
+		if sp.Background != nil {
+			fmt.Fprintf(&b, "%%{B%s}", sp.Background.ARGB())
+		}
+		if font {
+			fmt.Fprintf(&b, "%%{T%s}", sp.Font)
+		}
+		if sp.Underline {
+			b.WriteString("%{+u}")
+		}
+
+		b.WriteString(lemonbarEscape(sp.Text))
+
+		if sp.Underline {
+			b.WriteString("%{-u}")
+		}
+		if font {
+			b.WriteString("%{T-}")
+		}
+		if sp.Background != nil {
+			b.WriteString(reset(e.Background, "B"))
+		}
+		if sp.Color != nil {
+			b.WriteString(reset(e.Color, "F"))
+		}
+	}
+	return []byte(b.String())
+}
+
 // Write ...
 func (b *lemonbar) Write(v []Element) (err error) {
 	bytes := make([]byte, 0)
@@ -24,7 +81,6 @@ func (b *lemonbar) Write(v []Element) (err error) {
 		bytes = append(bytes, []byte{'%', '{', prefix}...)
 		bytes = append(bytes, data...)
 		bytes = append(bytes, '}')
-		_, err = b.out.Write(bytes)
 		return
 	}
 
@@ -53,20 +109,25 @@ func (b *lemonbar) Write(v []Element) (err error) {
 
 		// Colors.
 		if e.Color != nil {
-			format('F', []byte(e.Color.String()))
+			format('F', []byte(e.Color.ARGB()))
 		}
 		if e.Background != nil {
-			format('B', []byte(e.Background.String()))
+			format('B', []byte(e.Background.ARGB()))
 		}
 
 		// Contents.
-		str := strings.Replace(e.FullText, "%", "%%", -1)
-		bytes = append(bytes, []byte(str)...)
+		if len(e.Spans) > 0 {
+			bytes = append(bytes, lemonbarSpans(e)...)
+		} else {
+			bytes = append(bytes, []byte(lemonbarEscape(e.FullText))...)
+		}
 	}
 	bytes = append(bytes, '\n')
 	_, err = b.out.Write(bytes)
+	if err != nil {
+		return
+	}
 
 	// TODO: Support other formatting options.
-	b.out.Flush()
-	return
+	return b.out.Flush()
 }
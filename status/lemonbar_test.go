@@ -0,0 +1,34 @@
+package status
+
+import (
+	"testing"
+)
+
+func TestLemonbarSpans(t *testing.T) {
+	red, blue := RGB(255, 0, 0), RGB(0, 0, 255)
+
+	tests := []struct {
+		name  string
+		color *Color // of the element
+		sp    Span
+		want  string
+	}{
+		{"plain", nil, Span{Text: "x"}, "x"},
+		{"escaped", nil, Span{Text: "100% %{F-}"}, "100%% %%{F-}"},
+		{"color", &red, Span{Text: "x", Color: &blue}, "%{F#0000ff}x%{F#ff0000}"},
+		{"default color", nil, Span{Text: "x", Color: &blue}, "%{F#0000ff}x%{F-}"},
+		{"background", &red, Span{Text: "x", Background: &blue}, "%{B#0000ff}x%{B-}"},
+		{"font", nil, Span{Text: "x", Font: "2", Underline: true},
+			"%{T2}%{+u}x%{-u}%{T-}"},
+		{"font name", nil, Span{Text: "x", Font: "Sans}%{F#fff}"}, "x"},
+		{"all", &red, Span{Text: "%", Color: &blue, Font: "1"},
+			"%{F#0000ff}%{T1}%%%{T-}%{F#ff0000}"},
+	}
+
+	for _, test := range tests {
+		e := Element{Color: test.color, Spans: []Span{test.sp}}
+		if got := string(lemonbarSpans(e)); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
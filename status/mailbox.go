@@ -0,0 +1,54 @@
+package status
+
+import (
+	"sync"
+)
+
+// Mailbox holds the latest output of every widget (by index) until
+// it is consumed by the status. Newer output replaces older output
+// which has not yet been consumed, so putting never blocks.
+type Mailbox struct {
+	mu      sync.Mutex
+	slots   [][]Element
+	pending []bool
+
+	// Ready recieves a value whenever there is pending output.
+	Ready chan bool
+}
+
+// NewMailbox creates a mailbox with one slot per widget.
+func NewMailbox(widgetcount int) *Mailbox {
+	return &Mailbox{
+		slots:   make([][]Element, widgetcount),
+		pending: make([]bool, widgetcount),
+		Ready:   make(chan bool, 1),
+	}
+}
+
+// Put stores e as the latest output of the widget with the given index.
+func (m *Mailbox) Put(index int, e []Element) {
+	m.mu.Lock()
+	m.slots[index] = e
+	m.pending[index] = true
+	m.mu.Unlock()
+
+	select {
+	case m.Ready <- true:
+	default:
+	}
+}
+
+// Take returns the pending output of every widget and empties the slots.
+func (m *Mailbox) Take() (v []WidgetElem) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, ok := range m.pending {
+		if ok {
+			v = append(v, WidgetElem{i, m.slots[i]})
+			m.slots[i] = nil
+			m.pending[i] = false
+		}
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package status
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMailbox(t *testing.T) {
+	type put struct {
+		index int
+		text  string
+	}
+	tests := []struct {
+		name  string
+		puts  []put
+		want  []WidgetElem
+		ready bool
+	}{
+		{"empty", nil, nil, false},
+		{"one", []put{{1, "a"}},
+			[]WidgetElem{{1, []Element{{FullText: "a"}}}}, true},
+		{"latest wins", []put{{0, "a"}, {0, "b"}},
+			[]WidgetElem{{0, []Element{{FullText: "b"}}}}, true},
+		{"by index", []put{{2, "c"}, {0, "a"}},
+			[]WidgetElem{{0, []Element{{FullText: "a"}}}, {2, []Element{{FullText: "c"}}}}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := NewMailbox(3)
+			for _, p := range test.puts {
+				m.Put(p.index, []Element{{FullText: p.text}})
+			}
+
+			ready := false
+			select {
+			case <-m.Ready:
+				ready = true
+			default:
+			}
+			if ready != test.ready {
+				t.Errorf("ready: got %v, want %v", ready, test.ready)
+			}
+
+			if got := m.Take(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+			if got := m.Take(); got != nil {
+				t.Errorf("second take: got %v, want nothing", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Span is a run of text sharing the same formatting.
+type Span struct {
+	Text       string `json:"text"`
+	Bold       bool   `json:"bold,omitempty"`
+	Italic     bool   `json:"italic,omitempty"`
+	Underline  bool   `json:"underline,omitempty"`
+	Color      *Color `json:"color,omitempty"`
+	Background *Color `json:"background,omitempty"`
+
+	// Font is a font description for pango and dzen2 (eg. "Monospace 10"),
+	// lemonbar expects the 1-based index of a font given with -f.
+	Font string `json:"font,omitempty"`
+}
+
+// Text returns the text of the element without any markup, which is
+// the concatenated text of the spans if there are any.
+func (e Element) Text() string {
+	if len(e.Spans) == 0 {
+		return e.FullText
+	}
+	var b strings.Builder
+	for _, sp := range e.Spans {
+		b.WriteString(sp.Text)
+	}
+	return b.String()
+}
+
+var pangoEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"'", "&#39;",
+	"\"", "&quot;",
+)
+
+// PangoEscape escapes s for use as text in pango markup.
+func PangoEscape(s string) string {
+	return pangoEscaper.Replace(s)
+}
+
+// pangoAlpha returns the alpha of c as used by fgalpha
+// and bgalpha, which is between 1 and 65535.
+func pangoAlpha(c Color) int {
+	if c.Alpha() == 0 {
+		return 1
+	}
+	return int(c.Alpha()) * 257
+}
+
+// Pango returns the span formatted as pango markup.
+func (sp Span) Pango() string {
+	var attrs []string
+	if sp.Bold {
+		attrs = append(attrs, `weight="bold"`)
+	}
+	if sp.Italic {
+		attrs = append(attrs, `style="italic"`)
+	}
+	if sp.Underline {
+		attrs = append(attrs, `underline="single"`)
+	}
+	// Pango takes the alpha as a separate attribute.
+	if sp.Color != nil {
+		attrs = append(attrs, fmt.Sprintf(`foreground="%s"`, sp.Color.Hex()))
+		if !sp.Color.Opaque() {
+			attrs = append(attrs, fmt.Sprintf(`fgalpha="%d"`, pangoAlpha(*sp.Color)))
+		}
+	}
+	if sp.Background != nil {
+		attrs = append(attrs, fmt.Sprintf(`background="%s"`, sp.Background.Hex()))
+		if !sp.Background.Opaque() {
+			attrs = append(attrs, fmt.Sprintf(`bgalpha="%d"`, pangoAlpha(*sp.Background)))
+		}
+	}
+	if sp.Font != "" {
+		attrs = append(attrs, fmt.Sprintf(`font_desc="%s"`, PangoEscape(sp.Font)))
+	}
+
+	text := PangoEscape(sp.Text)
+	if len(attrs) == 0 {
+		return text
+	}
+	return fmt.Sprintf("<span %s>%s</span>", strings.Join(attrs, " "), text)
+}
+
+// PangoSpans returns spans formatted as pango markup.
+func PangoSpans(spans []Span) string {
+	var b strings.Builder
+	for _, sp := range spans {
+		b.WriteString(sp.Pango())
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package status
+
+import (
+	"testing"
+)
+
+func TestPango(t *testing.T) {
+	red := RGB(255, 0, 0)
+	halfRed := RGBA(255, 0, 0, 128)
+	invisible := RGBA(0, 0, 255, 0)
+
+	tests := []struct {
+		sp   Span
+		want string
+	}{
+		{Span{Text: "plain"}, "plain"},
+		{Span{Text: `a&b<c>'d"`}, "a&amp;b&lt;c&gt;&#39;d&quot;"},
+		{Span{Text: "x", Bold: true, Italic: true, Underline: true},
+			`<span weight="bold" style="italic" underline="single">x</span>`},
+		{Span{Text: "x", Color: &red}, `<span foreground="#ff0000">x</span>`},
+		{Span{Text: "x", Color: &halfRed},
+			`<span foreground="#ff0000" fgalpha="32896">x</span>`},
+		{Span{Text: "x", Background: &invisible},
+			`<span background="#0000ff" bgalpha="1">x</span>`},
+		{Span{Text: "<x>", Font: `Sans "Bold" & <10>`},
+			`<span font_desc="Sans &quot;Bold&quot; &amp; &lt;10&gt;">&lt;x&gt;</span>`},
+	}
+
+	for _, test := range tests {
+		if got := test.sp.Pango(); got != test.want {
+			t.Errorf("Pango(%+v) = %s, want %s", test.sp, got, test.want)
+		}
+	}
+
+	spans := []Span{{Text: "a&"}, {Text: "b", Bold: true}}
+	if got, want := PangoSpans(spans), `a&amp;<span weight="bold">b</span>`; got != want {
+		t.Errorf("PangoSpans = %s, want %s", got, want)
+	}
+}
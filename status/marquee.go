@@ -0,0 +1,207 @@
+package status
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rivo/uniseg"
+)
+
+// MarqueeOptions configures Marquee.
+type MarqueeOptions struct {
+	// Width is the number of columns shown of text which is longer
+	// than it, the rest is scrolled into view.
+	Width int
+
+	// Every is how often the text is advanced by one character,
+	// DefaultMarqueeEvery if zero.
+	Every time.Duration
+
+	// Pause is the number of ticks the start of the text is shown
+	// before it starts scrolling, and before every new loop.
+	Pause int
+
+	// Separator is shown between the end and the start of the text.
+	Separator string
+}
+
+// DefaultMarqueeEvery is used when MarqueeOptions.Every is zero.
+const DefaultMarqueeEvery = 500 * time.Millisecond
+
+type marquee struct {
+	g    ContextGenerator
+	opts MarqueeOptions
+}
+
+// Marquee decorates g, scrolling the text of its elements which are
+// wider than opts.Width. The scrolling starts over whenever the text
+// of the elements changes.
+func Marquee(g ContextGenerator, opts MarqueeOptions) ContextGenerator {
+	return marquee{g, opts}
+}
+
+// graphemes splits s into grapheme clusters and their widths.
+func graphemes(s string) (clusters []string, widths []int) {
+	state := -1
+	for len(s) > 0 {
+		var cluster string
+		var width int
+		cluster, s, width, state = uniseg.FirstGraphemeClusterInString(s, state)
+		clusters = append(clusters, cluster)
+		widths = append(widths, width)
+	}
+	return
+}
+
+// marqueeCell is a grapheme cluster of the scrolled text, span is the
+// index of the span of the element it is from, -1 if it has none.
+type marqueeCell struct {
+	text  string
+	width int
+	span  int
+}
+
+// cells splits the text of e followed by the separator into cells.
+func (m marquee) cells(e Element) (cells []marqueeCell) {
+	add := func(s string, span int) {
+		clusters, widths := graphemes(s)
+		for i := range clusters {
+			cells = append(cells, marqueeCell{clusters[i], widths[i], span})
+		}
+	}
+	if len(e.Spans) == 0 {
+		add(e.FullText, -1)
+	}
+	for i, sp := range e.Spans {
+		add(sp.Text, i)
+	}
+	add(m.opts.Separator, -1)
+	return
+}
+
+// frame returns the text of e scrolled step ticks, or e unchanged if
+// it is narrow enough to be shown as is. The spans of e are cut along
+// with the text, the separator and padding are unstyled.
+func (m marquee) frame(e Element, step int) Element {
+	if TextWidth(e.Text()) <= m.opts.Width {
+		return e
+	}
+
+	cells := m.cells(e)
+	pos := step % (len(cells) + m.opts.Pause)
+	offset := pos - m.opts.Pause
+	if offset < 0 {
+		offset = 0
+	}
+
+	var spans []Span
+	var b strings.Builder
+	width := 0
+	current := -2
+	for i := offset; ; i = (i + 1) % len(cells) {
+		c := cells[i]
+		if width+c.width > m.opts.Width {
+			break
+		}
+		if c.span != current {
+			sp := Span{}
+			if c.span >= 0 {
+				sp = e.Spans[c.span]
+				sp.Text = ""
+			}
+			spans = append(spans, sp)
+			current = c.span
+		}
+		spans[len(spans)-1].Text += c.text
+		b.WriteString(c.text)
+		width += c.width
+	}
+	// A wide character did not fit at the end.
+	pad := strings.Repeat(" ", m.opts.Width-width)
+	b.WriteString(pad)
+
+	e.FullText = b.String()
+	if len(e.Spans) > 0 {
+		if pad != "" {
+			spans = append(spans, Span{Text: pad})
+		}
+		e.Spans = spans
+	}
+	return e
+}
+
+// sameText returns true if a and b contain the same text.
+func sameText(a, b []Element) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Text() != b[i].Text() {
+			return false
+		}
+	}
+	return true
+}
+
+// Run implements ContextGenerator.
+func (m marquee) Run(ctx context.Context, sink Sink) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The output of g is collected in a mailbox of its own.
+	inner := sink
+	inner.mailbox = NewMailbox(sink.Index + 1)
+
+	errch := make(chan error, 1)
+	go func() {
+		errch <- m.g.Run(ctx, inner)
+	}()
+
+	every := m.opts.Every
+	if every <= 0 {
+		every = DefaultMarqueeEvery
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	var elems []Element
+	step := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return <-errch
+
+		case err := <-errch:
+			return err
+
+		case <-inner.mailbox.Ready:
+			for _, we := range inner.mailbox.Take() {
+				if !sameText(we.e, elems) {
+					step = 0
+				}
+				elems = we.e
+			}
+			break
+
+		case <-ticker.C:
+			scrolling := false
+			for _, e := range elems {
+				if TextWidth(e.Text()) > m.opts.Width {
+					scrolling = true
+				}
+			}
+			if !scrolling {
+				continue
+			}
+			step++
+			break
+		}
+
+		frame := make([]Element, len(elems))
+		for i, e := range elems {
+			frame[i] = m.frame(e, step)
+		}
+		sink.Put(frame)
+	}
+}
@@ -0,0 +1,53 @@
+package status
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarqueeFrame(t *testing.T) {
+	red := ColorFromHex("#FF0000")
+	m := marquee{opts: MarqueeOptions{Width: 4, Pause: 1, Separator: "|"}}
+	spans := []Span{{Text: "ab", Bold: true}, {Text: "cde", Color: &red}}
+
+	tests := []struct {
+		name  string
+		e     Element
+		step  int
+		want  string
+		spans []Span
+	}{
+		{"narrow", Element{FullText: "abc"}, 3, "abc", nil},
+		{"paused", Element{FullText: "abcdef"}, 1, "abcd", nil},
+		{"scrolled", Element{FullText: "abcdef"}, 3, "cdef", nil},
+		{"separator", Element{FullText: "abcdef"}, 5, "ef|a", nil},
+		{"loop", Element{FullText: "abcdef"}, 8, "abcd", nil},
+		{"wide", Element{FullText: "日本語"}, 2, "本語", nil},
+		{"wide padded", Element{FullText: "日本語"}, 3, "語| ", nil},
+		{"spans", Element{Spans: spans}, 2, "bcde",
+			[]Span{{Text: "b", Bold: true}, {Text: "cde", Color: &red}}},
+		{"spans separator", Element{Spans: spans}, 3, "cde|",
+			[]Span{{Text: "cde", Color: &red}, {Text: "|"}}},
+		{"spans wrapped", Element{Spans: spans}, 5, "e|ab",
+			[]Span{{Text: "e", Color: &red}, {Text: "|"}, {Text: "ab", Bold: true}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := m.frame(test.e, test.step)
+			if got.FullText != test.want {
+				t.Errorf("got %q, want %q", got.FullText, test.want)
+			}
+			if test.spans != nil && !reflect.DeepEqual(got.Spans, test.spans) {
+				t.Errorf("got spans %+v, want %+v", got.Spans, test.spans)
+			}
+			if test.spans != nil && got.Text() != test.want {
+				t.Errorf("got span text %q, want %q", got.Text(), test.want)
+			}
+		})
+	}
+
+	if spans[0].Text != "ab" {
+		t.Error("frame modified the spans of the element")
+	}
+}
@@ -0,0 +1,87 @@
+package status
+
+import (
+	"os"
+	"time"
+)
+
+// Option configures a Status created by New.
+type Option func(s *Status) error
+
+// New creates a new status writing to b, configured by opts.
+func New(b Bar, opts ...Option) (*Status, error) {
+	s := NewStatus(b)
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// WithWidgets adds the widgets in the given order, see AddWidget.
+func WithWidgets(widgets ...Widget) Option {
+	return func(s *Status) error {
+		for _, w := range widgets {
+			if err := s.AddWidget(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithStopSignal sets the stop signal, see SetStopSignal.
+func WithStopSignal(c <-chan os.Signal) Option {
+	return func(s *Status) error { return s.SetStopSignal(c) }
+}
+
+// WithContSignal sets the cont signal, see SetContSignal.
+func WithContSignal(c <-chan os.Signal) Option {
+	return func(s *Status) error { return s.SetContSignal(c) }
+}
+
+// WithTermSignal sets the term signal, see SetTermSignal.
+func WithTermSignal(c <-chan os.Signal) Option {
+	return func(s *Status) error { return s.SetTermSignal(c) }
+}
+
+// WithRefreshSignal sets the refresh signal channel, see SetRefreshSignal.
+func WithRefreshSignal(c <-chan os.Signal) Option {
+	return func(s *Status) error { return s.SetRefreshSignal(c) }
+}
+
+// WithClickEvents sets the click event channel, see SetClickEvents.
+func WithClickEvents(c <-chan ClickEvent) Option {
+	return func(s *Status) error { return s.SetClickEvents(c) }
+}
+
+// WithControlSocket sets the control socket path, see SetControlSocket.
+func WithControlSocket(path string) Option {
+	return func(s *Status) error { return s.SetControlSocket(path) }
+}
+
+// WithRedrawInterval sets the redraw interval, see SetRedrawInterval.
+func WithRedrawInterval(d time.Duration) Option {
+	return func(s *Status) error { return s.SetRedrawInterval(d) }
+}
+
+// WithOnce makes the status stop after writing once, see SetOnce.
+func WithOnce() Option {
+	return func(s *Status) error { return s.SetOnce(true) }
+}
+
+// WithOnceTimeout sets the once timeout, see SetOnceTimeout.
+func WithOnceTimeout(d time.Duration) Option {
+	return func(s *Status) error { return s.SetOnceTimeout(d) }
+}
+
+// WithShutdownTimeout sets the shutdown timeout, see SetShutdownTimeout.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(s *Status) error { return s.SetShutdownTimeout(d) }
+}
+
+// WithTheme sets the theme, see SetTheme.
+func WithTheme(t Theme) Option {
+	return func(s *Status) error { return s.SetTheme(t) }
+}
@@ -0,0 +1,31 @@
+package status
+
+import (
+	"strings"
+)
+
+type plain struct {
+	Bar
+	sep string
+	out BarWriter
+}
+
+// NewPlain implements the Bar interface and writes the text of the
+// elements, without any formatting, joined by sep.
+func NewPlain(sep string, out BarWriter) Bar {
+	return &plain{sep: sep, out: out}
+}
+
+// Write ...
+func (b *plain) Write(v []Element) (err error) {
+	texts := make([]string, 0, len(v))
+	for _, e := range v {
+		texts = append(texts, e.Text())
+	}
+
+	_, err = b.out.Write([]byte(strings.Join(texts, b.sep) + "\n"))
+	if err != nil {
+		return
+	}
+	return b.out.Flush()
+}
@@ -0,0 +1,103 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PolybarOptions configures the polybar output.
+type PolybarOptions struct {
+	// Padding is the number of spaces on each side of every element.
+	Padding int
+
+	// ClickCommand is run by polybar when an element is clicked, with
+	// the button, name and instance of the element as arguments (see
+	// the click command of go-status). Empty disables click actions.
+	ClickCommand string
+
+	// Overline draws Element.Border as an overline instead
+	// of an underline.
+	Overline bool
+}
+
+type polybar struct {
+	Bar
+	opts PolybarOptions
+	out  BarWriter
+}
+
+// NewPolybar implements the Bar interface and supports generating
+// output for a polybar custom/script module with tail = true.
+func NewPolybar(opts PolybarOptions, out BarWriter) Bar {
+	return &polybar{opts: opts, out: out}
+}
+
+// polybarActionEscaper escapes the characters which end the
+// command of an action tag, or start or end another tag in it.
+var polybarActionEscaper = strings.NewReplacer(":", "\\:", "%", "\\%", "}", "\\}")
+
+// action returns the command run when button is clicked on e.
+func (b *polybar) action(e Element, button int) string {
+	return polybarActionEscaper.Replace(clickCommandLine(b.opts.ClickCommand, e, button))
+}
+
+// Write ...
+func (b *polybar) Write(v []Element) (err error) {
+	var s strings.Builder
+
+	pad := strings.Repeat(" ", b.opts.Padding)
+	line := 'u'
+	if b.opts.Overline {
+		line = 'o'
+	}
+
+	for _, e := range v {
+		clickable := b.opts.ClickCommand != "" && e.Name != ""
+		if clickable {
+			for button := 1; button <= 3; button++ {
+				fmt.Fprintf(&s, "%%{A%d:%s:}", button, b.action(e, button))
+			}
+		}
+
+		// Colors.
+		if e.Background != nil {
+			fmt.Fprintf(&s, "%%{B%s}", e.Background.ARGB())
+		}
+		if e.Color != nil {
+			fmt.Fprintf(&s, "%%{F%s}", e.Color.ARGB())
+		}
+		if e.Border != nil {
+			fmt.Fprintf(&s, "%%{%c%s}%%{+%c}", line, e.Border.ARGB(), line)
+		}
+
+		// Contents, using the same formatting tags as lemonbar.
+		s.WriteString(pad)
+		if len(e.Spans) > 0 {
+			s.Write(lemonbarSpans(e))
+		} else {
+			s.WriteString(lemonbarEscape(e.FullText))
+		}
+		s.WriteString(pad)
+
+		// Reset everything so it does not leak into the next element.
+		if e.Border != nil {
+			fmt.Fprintf(&s, "%%{-%c}", line)
+		}
+		if e.Color != nil {
+			s.WriteString("%{F-}")
+		}
+		if e.Background != nil {
+			s.WriteString("%{B-}")
+		}
+		if clickable {
+			s.WriteString("%{A}%{A}%{A}")
+		}
+	}
+	s.WriteByte('\n')
+
+	_, err = b.out.Write([]byte(s.String()))
+	if err != nil {
+		return
+	}
+	return b.out.Flush()
+}
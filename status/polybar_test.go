@@ -0,0 +1,25 @@
+package status
+
+import (
+	"testing"
+)
+
+func TestPolybarAction(t *testing.T) {
+	tests := []struct {
+		name, instance string
+		want           string
+	}{
+		{"clock", "", `go-status click 1 'clock' ''`},
+		{"a:b", "c", `go-status click 1 'a\:b' 'c'`},
+		{"100%", "", `go-status click 1 '100\%' ''`},
+		{"x}y", "%{F-}", `go-status click 1 'x\}y' '\%{F-\}'`},
+	}
+
+	b := &polybar{opts: PolybarOptions{ClickCommand: "go-status click"}}
+	for _, test := range tests {
+		e := Element{Name: test.name, Instance: test.instance}
+		if got := b.action(e, 1); got != test.want {
+			t.Errorf("action(%q, %q) = %s, want %s", test.name, test.instance, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package status
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"syscall"
+)
+
+// SigRtMin is the first realtime signal available to programs on linux.
+// (The kernel value is 32, but glibc reserves the first two.)
+const SigRtMin = syscall.Signal(34)
+
+// SigRtMax is the last realtime signal on linux.
+const SigRtMax = syscall.Signal(64)
+
+// RefreshSignal returns the realtime signal SIGRTMIN+n.
+func RefreshSignal(n int) syscall.Signal {
+	return SigRtMin + syscall.Signal(n)
+}
+
+// RefreshSignals returns the realtime signals used by the widgets
+// which have a Signal set, suitable for passing to signal.Notify.
+func (s *Status) RefreshSignals() (sigs []os.Signal) {
+	for _, w := range s.widgets {
+		if w.Signal > 0 {
+			sigs = append(sigs, RefreshSignal(w.Signal))
+		}
+	}
+	return
+}
+
+// SetRefreshSignal sets the channel on which the realtime signals
+// returned by RefreshSignals are recieved.
+func (s *Status) SetRefreshSignal(c <-chan os.Signal) error {
+	if s.started {
+		return fmt.Errorf("cannot set refresh signal: %w", ErrStarted)
+	}
+	s.sigrefreshch = c
+	return nil
+}
+
+// refresh requests the widget with the given index to regenerate.
+// Does not block if a refresh is already pending.
+func (s *Status) refresh(index int) {
+	select {
+	case s.refreshch[index] <- true:
+	default:
+	}
+}
+
+// matches returns true if the widget with the given index is known
+// by name, either by its Name or by the Name or Instance of one
+// of its current elements.
+func (s *Status) matches(index int, name string) bool {
+	if s.widgets[index].Name == name {
+		return true
+	}
+	for _, e := range s.cache[index] {
+		if e.Name == name || e.Instance == name {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshByName requests a refresh of every widget matching name
+// and returns the number of widgets which matched.
+func (s *Status) refreshByName(name string) (n int) {
+	for i := range s.widgets {
+		if s.matches(i, name) {
+			s.refresh(i)
+			n++
+		}
+	}
+	return
+}
+
+// refreshBySignal requests a refresh of every widget using sig.
+func (s *Status) refreshBySignal(sig os.Signal) {
+	for i, w := range s.widgets {
+		if w.Signal > 0 && RefreshSignal(w.Signal) == sig {
+			log.Printf("Recieved refresh signal %v, refreshing widget #%d\n", sig, i)
+			s.refresh(i)
+		}
+	}
+}
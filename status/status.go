@@ -1,10 +1,14 @@
 package status
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"syscall"
+	"time"
 )
 
 // BarWriter is an interface wrapping an io.Writer with
@@ -34,6 +38,26 @@ type Element struct {
 	Urgent              bool     `json:"urgent,omitempty"`
 	Separator           bool     `json:"separator,omitempty"`
 	SeparatorBlockWidth int      `json:"separator_block_width,omitempty"`
+	Markup              string   `json:"markup,omitempty"` // "pango" or "none"
+
+	// Spans is rich text which replaces FullText when set, it is
+	// translated to the markup supported by each bar.
+	Spans []Span `json:"spans,omitempty"`
+
+	// Used by bars which support them, eg. waybar.
+	Tooltip    string `json:"tooltip,omitempty"`
+	Percentage *int   `json:"percentage,omitempty"`
+	Alt        string `json:"alt,omitempty"`
+
+	// ColorRole and BackgroundRole are replaced by the colors of the
+	// roles in the theme of the status, see SetTheme. Color and
+	// Background are kept if the theme does not have the role.
+	ColorRole      Role `json:"color_role,omitempty"`
+	BackgroundRole Role `json:"background_role,omitempty"`
+
+	// Priority decides which elements are shortened first when the
+	// bar is short on space, lower goes first. See Budget.
+	Priority int `json:"priority,omitempty"`
 }
 
 // AlignStr represents the various ways to aligning widgets.
@@ -56,94 +80,329 @@ type Status struct {
 	sigstopch <-chan os.Signal
 	sigcontch <-chan os.Signal
 	sigtermch <-chan os.Signal
+
+	sigrefreshch <-chan os.Signal
+
+	clickch <-chan ClickEvent
+
+	ctlpath   string
+	ctlch     chan ctlRequest
+	done      chan bool // closed when Run returns
+	hidden    []bool
+	messages  []message
+	msgtimer  *time.Timer
+	updates   []int
+	updated   []time.Time
+	failed    []bool
+	startTime time.Time
+
+	redrawInterval  time.Duration
+	shutdownTimeout time.Duration
+	once            bool
+	onceTimeout     time.Duration
+	theme           Theme
+
+	refreshch []chan bool
 }
 
+// DefaultShutdownTimeout is the time to wait for the generators
+// to return when shutting down.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// DefaultOnceTimeout is the time to wait for the first output of
+// every widget in once mode.
+const DefaultOnceTimeout = 5 * time.Second
+
+// ErrStarted is returned when attempting to modify or start
+// an already started status.
+var ErrStarted = errors.New("status already started")
+
 // NewStatus creates a new status.
 func NewStatus(b Bar) Status {
-	return Status{b: b}
+	return Status{b: b, shutdownTimeout: DefaultShutdownTimeout,
+		onceTimeout: DefaultOnceTimeout, theme: DefaultTheme}
 }
 
 // AddWidget adds the given widget to the slice of widgets to be
 // displayed on the statusline. The order of AddWidget calls is the
 // order which is used for the statusline. (May differ based upon
-// alignment.)
-func (s *Status) AddWidget(w Widget) {
+// alignment.) The Gen of the widget must be either a Generator or
+// a ContextGenerator.
+func (s *Status) AddWidget(w Widget) error {
 	if s.started {
-		panic(fmt.Errorf("cannot add a widget to a started status"))
+		return fmt.Errorf("cannot add a widget: %w", ErrStarted)
+	}
+	if _, ok := contextGenerator(w.Gen); !ok {
+		return fmt.Errorf("widget %s: %T is not a Generator or ContextGenerator", w.Name, w.Gen)
+	}
+	if w.Signal < 0 || RefreshSignal(w.Signal) > SigRtMax {
+		return fmt.Errorf("invalid refresh signal of widget %s: %d, must be between 0 and %d",
+			w.Name, w.Signal, SigRtMax-SigRtMin)
 	}
 	s.widgets = append(s.widgets, w)
+	return nil
 }
 
 // SetStopSignal sets stop signal, usually this would be SIGSTOP.
-func (s *Status) SetStopSignal(c <-chan os.Signal) {
+func (s *Status) SetStopSignal(c <-chan os.Signal) error {
 	if s.started {
-		panic(fmt.Errorf("cannot set stop signal since status already started"))
+		return fmt.Errorf("cannot set stop signal: %w", ErrStarted)
 	}
 	s.sigstopch = c
+	return nil
 }
 
 // SetContSignal sets cont signal, usually this would be SIGCONT.
-func (s *Status) SetContSignal(c <-chan os.Signal) {
+func (s *Status) SetContSignal(c <-chan os.Signal) error {
 	if s.started {
-		panic(fmt.Errorf("cannot set cont signal since status already started"))
+		return fmt.Errorf("cannot set cont signal: %w", ErrStarted)
 	}
 	s.sigcontch = c
+	return nil
 }
 
 // SetTermSignal sets sigterm signal, usually this would be SIGTERM.
-func (s *Status) SetTermSignal(c <-chan os.Signal) {
+func (s *Status) SetTermSignal(c <-chan os.Signal) error {
 	if s.started {
-		panic(fmt.Errorf("cannot set sigterm signal since status already started"))
+		return fmt.Errorf("cannot set sigterm signal: %w", ErrStarted)
 	}
 	s.sigtermch = c
+	return nil
+}
+
+// SetClickEvents sets the channel on which clicks on the elements are
+// recieved, eg. from ReadI3BarClicks. See Clicker.
+func (s *Status) SetClickEvents(c <-chan ClickEvent) error {
+	if s.started {
+		return fmt.Errorf("cannot set click events: %w", ErrStarted)
+	}
+	s.clickch = c
+	return nil
+}
+
+// SetRedrawInterval sets the minimum interval between two writes to
+// the bar. Changes recieved in between are coalesced into a single
+// write once the interval has passed. 0 writes on every change.
+func (s *Status) SetRedrawInterval(d time.Duration) error {
+	if s.started {
+		return fmt.Errorf("cannot set redraw interval: %w", ErrStarted)
+	}
+	s.redrawInterval = d
+	return nil
+}
+
+// SetShutdownTimeout sets the time to wait for the generators to
+// return when shutting down.
+func (s *Status) SetShutdownTimeout(d time.Duration) error {
+	if s.started {
+		return fmt.Errorf("cannot set shutdown timeout: %w", ErrStarted)
+	}
+	s.shutdownTimeout = d
+	return nil
+}
+
+// SetOnce makes the status write to the bar once, as soon as every
+// widget has produced its first output (or failed), and then stop.
+// See SetOnceTimeout.
+func (s *Status) SetOnce(once bool) error {
+	if s.started {
+		return fmt.Errorf("cannot set once: %w", ErrStarted)
+	}
+	s.once = once
+	return nil
+}
+
+// SetOnceTimeout sets the time to wait for the first output of every
+// widget in once mode. The widgets which have not produced any output
+// by then are shown as errors, eg. those which stream their output.
+func (s *Status) SetOnceTimeout(d time.Duration) error {
+	if s.started {
+		return fmt.Errorf("cannot set once timeout: %w", ErrStarted)
+	}
+	s.onceTimeout = d
+	return nil
+}
+
+// setError replaces the elements of the widget with the
+// given index by an element showing err.
+func (s *Status) setError(i int, err error) {
+	s.widgets[i].Error = err
+	s.failed[i] = true
+	s.cache[i] = []Element{Element{Name: "error",
+		Alignment: AlignRight,
+		ColorRole: RoleBad,
+		FullText:  fmt.Sprintf("ERROR: %v", err),
+		ShortText: "ERROR"}}
+}
+
+// allProduced returns true if every widget has produced
+// output or failed.
+func (s *Status) allProduced() bool {
+	for i := range s.widgets {
+		if s.updates[i] == 0 && !s.failed[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // Start starts the status loop which will run until the
-// term signal is recieved.
+// term signal is recieved. Errors are logged, see Run.
 func (s *Status) Start() {
+	if err := s.Run(context.Background()); err != nil {
+		log.Printf("Status stopped: %v\n", err)
+	}
+}
+
+// Run runs the status loop until ctx is cancelled, the term signal is
+// recieved or writing to the bar fails. The bar closing its end of the
+// pipe (EPIPE) is not considered an error.
+func (s *Status) Run(ctx context.Context) error {
 	if s.started {
-		panic(fmt.Errorf("attempting to start an already started status"))
+		return ErrStarted
 	}
 	s.started = true
+	s.startTime = time.Now()
 	s.cache = make([][]Element, len(s.widgets))
+	s.hidden = make([]bool, len(s.widgets))
+	s.updates = make([]int, len(s.widgets))
+	s.updated = make([]time.Time, len(s.widgets))
+	s.failed = make([]bool, len(s.widgets))
+	s.ctlch = make(chan ctlRequest)
+	s.done = make(chan bool)
+	defer close(s.done)
+	s.refreshch = make([]chan bool, len(s.widgets))
+	for i := range s.refreshch {
+		s.refreshch[i] = make(chan bool, 1)
+	}
 
-	update := func() {
+	running := true
+	var runErr error
+
+	write := func() {
 		v := make([]Element, 0, len(s.cache))
 
-		for i, _ := range s.widgets {
+		for i := range s.widgets {
+			if s.hidden[i] {
+				continue
+			}
 			elems := s.cache[i]
 			for _, e := range elems {
-				v = append(v, e)
+				if e.Priority == 0 {
+					e.Priority = s.widgets[i].Priority
+				}
+				v = append(v, s.theme.resolve(e))
 			}
 		}
+		for _, m := range s.messages {
+			v = append(v, s.theme.resolve(m.e))
+		}
 
-		//data, err := json.Marshal(v)
-		//if err != nil {
-		//	panic(err)
-		//}
-		//s.w.Write(data)
 		err := s.b.Write(v)
-		if err != nil {
-			panic(err)
+		if err == nil || !running {
+			return
+		}
+		running = false
+		if errors.Is(err, syscall.EPIPE) {
+			log.Println("Bar closed the pipe, shutting down!")
+			return
+		}
+		runErr = fmt.Errorf("unable to write to bar: %w", err)
+	}
+
+	// Writes to the bar at most once every redrawInterval,
+	// redrawch is non-nil while a write is pending.
+	var lastWrite time.Time
+	var redrawch <-chan time.Time
+	update := func() {
+		// In once mode the bar is only written when
+		// every widget has produced output.
+		if redrawch != nil || s.once {
+			return
+		}
+		wait := s.redrawInterval - time.Since(lastWrite)
+		if wait > 0 {
+			redrawch = time.After(wait)
+			return
 		}
+		lastWrite = time.Now()
+		write()
 	}
 
-	ctx := NewGeneratorCtx(len(s.widgets))
+	mailbox := NewMailbox(len(s.widgets))
+	errch := make(chan WidgetError, len(s.widgets))
+
+	if s.ctlpath != "" {
+		l, err := s.listenControl()
+		if err != nil {
+			log.Printf("Unable to listen on control socket: %v\n", err)
+		} else {
+			defer os.Remove(s.ctlpath)
+			defer l.Close()
+		}
+	}
 
 	// Start goroutines.
-	for i, widget := range s.widgets {
-		go widget.Gen.Generate(&widget, i, &ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	var g group
+	for i := range s.widgets {
+		sink := Sink{Widget: &s.widgets[i], Index: i,
+			Refresh: s.refreshch[i], mailbox: mailbox}
+		gen, _ := contextGenerator(s.widgets[i].Gen)
+		g.Go(func() {
+			err := gen.Run(ctx, sink)
+			if err != nil && ctx.Err() == nil {
+				errch <- WidgetError{sink.Index, err}
+			}
+		})
+	}
+
+	// In once mode, widgets which never produce output, eg. those
+	// waiting for events, must not keep the status running forever.
+	var oncech <-chan time.Time
+	if s.once {
+		timer := time.NewTimer(s.onceTimeout)
+		defer timer.Stop()
+		oncech = timer.C
 	}
 
 	// Loop until a term signal is recieved.
-	running := true
 	for running {
 		select {
-		case we := <-ctx.Ch:
-			s.cache[we.Index] = we.e
+		case <-ctx.Done():
+			log.Println("Context cancelled, shutting down!")
+			running = false
+			break
+
+		case <-mailbox.Ready:
+			for _, we := range mailbox.Take() {
+				s.cache[we.Index] = we.e
+				s.updates[we.Index]++
+				s.updated[we.Index] = time.Now()
+			}
 			update()
 			break
 
+		case c := <-s.ctlch:
+			resp, redraw := s.handleControl(c.req)
+			c.reply <- resp
+			if redraw {
+				update()
+			}
+			break
+
+		case <-redrawch:
+			redrawch = nil
+			lastWrite = time.Now()
+			write()
+			break
+
+		case <-s.messageTimeout():
+			if s.expireMessages() {
+				update()
+			}
+			break
+
 		case <-s.sigstopch:
 			log.Println("Recieved stop signal, stopping!")
 
@@ -163,6 +422,11 @@ func (s *Status) Start() {
 				running = false
 				log.Println("Recieved term signal while stopped, shutting down!")
 				break
+
+			case <-ctx.Done():
+				running = false
+				log.Println("Context cancelled while stopped, shutting down!")
+				break
 			}
 			break
 
@@ -170,45 +434,52 @@ func (s *Status) Start() {
 			log.Println("Recieved cont signal while running, ignoring!")
 			break
 
+		case sig := <-s.sigrefreshch:
+			s.refreshBySignal(sig)
+			break
+
+		case ev := <-s.clickch:
+			if s.click(ev) == 0 {
+				log.Printf("Recieved click on unknown element: %s %s\n", ev.Name, ev.Instance)
+			}
+			break
+
 		case <-s.sigtermch:
 			log.Println("Recieved term signal, shutting down!")
 			running = false
 			break
 
-		case werror := <-ctx.Errorch:
+		case werror := <-errch:
 			log.Printf("Recieved widget error, updating: %d, %v\n", werror.Index, werror.Error)
-			red := ColorFromHex("#FF0000")
-			s.cache[werror.Index] = []Element{Element{Name: "error",
-				Alignment: AlignRight,
-				Color:     &red,
-				FullText:  fmt.Sprintf("ERROR: %v", werror.Error)}}
+			s.setError(werror.Index, werror.Error)
 			update()
 			break
+
+		case <-oncech:
+			for i := range s.widgets {
+				if s.updates[i] == 0 && !s.failed[i] {
+					log.Printf("Widget %d produced no output within %v\n", i, s.onceTimeout)
+					s.setError(i, fmt.Errorf("no output within %v", s.onceTimeout))
+				}
+			}
+			break
 		}
-	}
 
-	// Stop widget generators.
-	log.Println("Sending stop to all widgets.")
-	for i := 0; i < len(s.widgets); i++ {
-		ctx.Stop <- true
+		if s.once && running && s.allProduced() {
+			log.Println("All widgets produced output once, shutting down!")
+			write()
+			running = false
+		}
 	}
 
-	// Wait for all widgets to send done.
-	// Consume products while waiting since some widgets
-	// may still have uncomsumed channel messages.
-	log.Println("Waiting for done messages.")
-	remaining := len(s.widgets)
-	for remaining != 0 {
-		select {
-		case <-ctx.Done:
-			remaining--
-			break
-		case <-ctx.Ch:
-			break
-		case <-ctx.Errorch:
-			break
-		}
+	// Stop widget generators and wait for them to return.
+	log.Println("Cancelling all widgets.")
+	cancel()
+	if !g.WaitTimeout(s.shutdownTimeout) {
+		log.Printf("Widgets did not stop within %v. Shutting down anyway.\n", s.shutdownTimeout)
+		return runErr
 	}
 
 	log.Println("Stopped all widgets. Shutting down.")
+	return runErr
 }
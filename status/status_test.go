@@ -0,0 +1,199 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordBar records every write.
+type recordBar struct {
+	writes [][]Element
+}
+
+func (b *recordBar) Write(v []Element) error {
+	b.writes = append(b.writes, v)
+	return nil
+}
+
+// testGen publishes its elements, or returns err, and then blocks.
+type testGen struct {
+	e     []Element
+	err   error
+	never bool // never publishes any output
+}
+
+func (g testGen) Run(ctx context.Context, sink Sink) error {
+	if g.err != nil {
+		return g.err
+	}
+	if !g.never {
+		sink.Put(g.e)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// legacyGen is a Generator which publishes text once.
+type legacyGen struct {
+	text string
+}
+
+func (g legacyGen) Generate(w *Widget, index int, ctx *GeneratorCtx) {
+	Generatorfunc(w, index, ctx, nil, func() ([]Element, error) {
+		return []Element{{FullText: g.text}}, nil
+	})
+}
+
+func TestOnce(t *testing.T) {
+	tests := []struct {
+		name string
+		gens []interface{}
+		want []string
+	}{
+		{"all produce",
+			[]interface{}{testGen{e: []Element{{FullText: "a"}}}, testGen{e: []Element{{FullText: "b"}}}},
+			[]string{"a", "b"}},
+		{"failed",
+			[]interface{}{testGen{e: []Element{{FullText: "a"}}}, testGen{err: errors.New("boom")}},
+			[]string{"a", "ERROR: boom"}},
+		{"never produces",
+			[]interface{}{testGen{never: true}, testGen{e: []Element{{FullText: "b"}}}},
+			[]string{"ERROR: no output within 50ms", "b"}},
+		{"generator",
+			[]interface{}{legacyGen{"a"}, testGen{e: []Element{{FullText: "b"}}}},
+			[]string{"a", "b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &recordBar{}
+			opts := []Option{WithOnce(), WithOnceTimeout(50 * time.Millisecond)}
+			for _, g := range test.gens {
+				opts = append(opts, WithWidgets(Widget{Gen: g}))
+			}
+			s, err := New(b, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := s.Run(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if ctx.Err() != nil {
+				t.Fatal("once mode did not stop")
+			}
+			if len(b.writes) != 1 {
+				t.Fatalf("got %d writes, want 1", len(b.writes))
+			}
+
+			var got []string
+			for _, e := range b.writes[0] {
+				got = append(got, e.FullText)
+			}
+			if strings.Join(got, "|") != strings.Join(test.want, "|") {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAddWidgetGen(t *testing.T) {
+	tests := []struct {
+		gen interface{}
+		ok  bool
+	}{
+		{testGen{}, true},
+		{legacyGen{}, true},
+		{nil, false},
+		{"text", false},
+	}
+
+	for _, test := range tests {
+		s := NewStatus(&recordBar{})
+		err := s.AddWidget(Widget{Gen: test.gen})
+		if (err == nil) != test.ok {
+			t.Errorf("AddWidget with %T: got error %v", test.gen, err)
+		}
+	}
+}
+
+func TestAddWidgetSignal(t *testing.T) {
+	tests := []struct {
+		signal int
+		ok     bool
+	}{
+		{0, true},
+		{1, true},
+		{30, true},
+		{31, false},
+		{-1, false},
+	}
+
+	for _, test := range tests {
+		s := NewStatus(&recordBar{})
+		err := s.AddWidget(Widget{Gen: testGen{}, Signal: test.signal})
+		if (err == nil) != test.ok {
+			t.Errorf("AddWidget with signal %d: got error %v", test.signal, err)
+		}
+	}
+}
+
+// burstGen publishes first, and then each of burst right away.
+type burstGen struct {
+	first string
+	burst []string
+}
+
+func (g burstGen) Run(ctx context.Context, sink Sink) error {
+	sink.Put([]Element{{FullText: g.first}})
+	// Let the first one be written before the burst.
+	time.Sleep(20 * time.Millisecond)
+	for _, text := range g.burst {
+		sink.Put([]Element{{FullText: text}})
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestRedrawInterval(t *testing.T) {
+	tests := []struct {
+		name string
+		gen  burstGen
+	}{
+		{"single", burstGen{"a", []string{"b"}}},
+		{"burst", burstGen{"a", []string{"b", "c", "d", "e", "f", "g"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &recordBar{}
+			s, err := New(b, WithWidgets(Widget{Gen: test.gen}),
+				WithRedrawInterval(200*time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			if err := s.Run(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, v := range b.writes {
+				got = append(got, v[0].FullText)
+			}
+			// The first is written right away, the burst within the
+			// interval after it is coalesced into the newest state.
+			want := []string{test.gen.first, test.gen.burst[len(test.gen.burst)-1]}
+			if strings.Join(got, "|") != strings.Join(want, "|") {
+				t.Errorf("got writes %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Strftime formats t according to the strftime(3) format f. The
+// supported conversions are:
+//
+//	%a %A %b %B %c %C %d %D %e %F %g %G %h %H %I %j %k %l %m %M
+//	%n %p %P %r %R %s %S %t %T %u %V %w %x %X %y %Y %z %Z %%
+//
+// Unsupported conversions are left as is.
+func Strftime(t time.Time, f string) string {
+	var b strings.Builder
+	conv := false
+	for _, c := range f {
+		switch {
+		case conv:
+			b.WriteString(strftimeConv(t, c))
+			conv = false
+		case c == '%':
+			conv = true
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if conv {
+		b.WriteByte('%')
+	}
+	return b.String()
+}
+
+func strftimeConv(t time.Time, c rune) string {
+	switch c {
+	case 'a':
+		return t.Format("Mon")
+	case 'A':
+		return t.Format("Monday")
+	case 'b', 'h':
+		return t.Format("Jan")
+	case 'B':
+		return t.Format("January")
+	case 'c':
+		return t.Format("Mon Jan _2 15:04:05 2006")
+	case 'C':
+		return fmt.Sprintf("%02d", t.Year()/100)
+	case 'd':
+		return t.Format("02")
+	case 'D', 'x':
+		return t.Format("01/02/06")
+	case 'e':
+		return t.Format("_2")
+	case 'F':
+		return t.Format("2006-01-02")
+	case 'g':
+		year, _ := t.ISOWeek()
+		return fmt.Sprintf("%02d", year%100)
+	case 'G':
+		year, _ := t.ISOWeek()
+		return fmt.Sprintf("%d", year)
+	case 'H':
+		return t.Format("15")
+	case 'I':
+		return t.Format("03")
+	case 'j':
+		return fmt.Sprintf("%03d", t.YearDay())
+	case 'k':
+		return fmt.Sprintf("%2d", t.Hour())
+	case 'l':
+		hour := t.Hour() % 12
+		if hour == 0 {
+			hour = 12
+		}
+		return fmt.Sprintf("%2d", hour)
+	case 'm':
+		return t.Format("01")
+	case 'M':
+		return t.Format("04")
+	case 'n':
+		return "\n"
+	case 'p':
+		return t.Format("PM")
+	case 'P':
+		return t.Format("pm")
+	case 'r':
+		return t.Format("03:04:05 PM")
+	case 'R':
+		return t.Format("15:04")
+	case 's':
+		return fmt.Sprintf("%d", t.Unix())
+	case 'S':
+		return t.Format("05")
+	case 't':
+		return "\t"
+	case 'T', 'X':
+		return t.Format("15:04:05")
+	case 'u':
+		wd := int(t.Weekday())
+		if wd == 0 {
+			wd = 7
+		}
+		return fmt.Sprintf("%d", wd)
+	case 'V':
+		_, week := t.ISOWeek()
+		return fmt.Sprintf("%02d", week)
+	case 'w':
+		return fmt.Sprintf("%d", int(t.Weekday()))
+	case 'y':
+		return t.Format("06")
+	case 'Y':
+		return fmt.Sprintf("%d", t.Year())
+	case 'z':
+		return t.Format("-0700")
+	case 'Z':
+		return t.Format("MST")
+	case '%':
+		return "%"
+	}
+	return "%" + string(c)
+}
+
+// FormatTime formats t using f, which is a strftime(3) format if it
+// contains a % and a time.Format layout otherwise.
+func FormatTime(t time.Time, f string) string {
+	if strings.Contains(f, "%") {
+		return Strftime(t, f)
+	}
+	return t.Format(f)
+}
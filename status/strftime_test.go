@@ -0,0 +1,65 @@
+package status
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrftime(t *testing.T) {
+	zone := time.FixedZone("CET", 3600)
+	midnight := time.Date(2021, time.January, 3, 0, 5, 9, 0, zone)
+	afternoon := time.Date(2024, time.March, 14, 13, 7, 0, 0, time.UTC)
+
+	tests := []struct {
+		t    time.Time
+		f    string
+		want string
+	}{
+		{midnight, "%a %A %b %B %h", "Sun Sunday Jan January Jan"},
+		{midnight, "%c", "Sun Jan  3 00:05:09 2021"},
+		{midnight, "%C %y %Y", "20 21 2021"},
+		{midnight, "%d %e %j", "03  3 003"},
+		{midnight, "%D %x %F", "01/03/21 01/03/21 2021-01-03"},
+		{midnight, "%g %G %V", "20 2020 53"},
+		{midnight, "%H %I %k %l", "00 12  0 12"},
+		{afternoon, "%H %I %k %l", "13 01 13  1"},
+		{midnight, "%m %M %S", "01 05 09"},
+		{midnight, "%p %P", "AM am"},
+		{afternoon, "%p %P %r", "PM pm 01:07:00 PM"},
+		{midnight, "%R %T %X", "00:05 00:05:09 00:05:09"},
+		{midnight, "%s", "1609628709"},
+		{midnight, "%u %w", "7 0"},
+		{afternoon, "%u %w", "4 4"},
+		{midnight, "%z %Z", "+0100 CET"},
+		{midnight, "a%nb%tc", "a\nb\tc"},
+		{midnight, "100%%", "100%"},
+		{midnight, "%q", "%q"},
+		{midnight, "trailing %", "trailing %"},
+		{midnight, "%ø %H", "%ø 00"},
+		{midnight, "kl. %H — uke %V", "kl. 00 — uke 53"},
+	}
+
+	for _, test := range tests {
+		if got := Strftime(test.t, test.f); got != test.want {
+			t.Errorf("Strftime(%q) = %q, want %q", test.f, got, test.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	tm := time.Date(2024, time.March, 14, 13, 7, 0, 0, time.UTC)
+
+	tests := []struct {
+		f, want string
+	}{
+		{"15:04", "13:07"},
+		{"%H:%M", "13:07"},
+		{"Mon Jan 2", "Thu Mar 14"},
+	}
+
+	for _, test := range tests {
+		if got := FormatTime(tm, test.f); got != test.want {
+			t.Errorf("FormatTime(%q) = %q, want %q", test.f, got, test.want)
+		}
+	}
+}
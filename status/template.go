@@ -0,0 +1,153 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Fields are the named values a widget exposes to its Template,
+// eg. {{.charge}} for the "charge" field.
+type Fields map[string]interface{}
+
+// Template formats the Fields of a widget as text. It uses the
+// text/template syntax with the functions in TemplateFuncs, eg:
+//
+//	{{.charge}}%{{if eq .status "charging"}} +{{end}} {{hm .remaining}}
+type Template struct {
+	t *template.Template
+}
+
+// TemplateFuncs are the helper functions available in a Template.
+var TemplateFuncs = template.FuncMap{
+	// lpad and rpad pad the value with spaces to the given width.
+	"lpad": func(width int, v interface{}) string {
+		s := fmt.Sprint(v)
+		return padding(width, s) + s
+	},
+	"rpad": func(width int, v interface{}) string {
+		s := fmt.Sprint(v)
+		return s + padding(width, s)
+	},
+	// fixed formats a number with prec decimals.
+	"fixed": func(prec int, v interface{}) (string, error) {
+		f, err := toFloat(v)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'f', prec, 64), nil
+	},
+	// hm formats a duration as hours and minutes, eg. 1:05.
+	"hm": func(d time.Duration) string {
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	},
+	"truncate": func(width int, s string) string {
+		return Truncate(s, width)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// padding returns the spaces needed to pad s to width.
+func padding(width int, s string) string {
+	n := width - TextWidth(s)
+	if n < 0 {
+		return ""
+	}
+	return strings.Repeat(" ", n)
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint:
+		return float64(n), nil
+	}
+	return 0, fmt.Errorf("not a number: %v", v)
+}
+
+// ParseTemplate parses text as a Template.
+func ParseTemplate(text string) (*Template, error) {
+	t, err := template.New("").Funcs(TemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{t}, nil
+}
+
+// MustParseTemplate is like ParseTemplate but panics if text is
+// invalid. It is intended for templates known at compile time.
+func MustParseTemplate(text string) *Template {
+	t, err := ParseTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Execute returns the text of the template for the given fields.
+func (t *Template) Execute(fields Fields) (string, error) {
+	var b strings.Builder
+	if err := t.t.Execute(&b, fields); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ApplyTemplates replaces the FullText (and Spans) of e with the output
+// of full, and its ShortText with the output of short. Nil templates
+// are skipped. If a template fails the error is returned and shown as
+// the text of e instead, so the widget keeps running.
+func ApplyTemplates(e *Element, full, short *Template, fields Fields) (err error) {
+	defer func() {
+		if err != nil {
+			e.FullText = fmt.Sprintf("template error: %v", err)
+			e.ShortText = "template error"
+			e.Spans = nil
+			e.Color = nil
+			e.ColorRole = RoleBad
+		}
+	}()
+
+	if full != nil {
+		text, err := full.Execute(fields)
+		if err != nil {
+			return err
+		}
+		e.FullText = text
+		e.Spans = nil
+	}
+	if short != nil {
+		text, err := short.Execute(fields)
+		if err != nil {
+			return err
+		}
+		e.ShortText = text
+	}
+	return nil
+}
+
+// UnmarshalJSON parses a template from a JSON string, see ParseTemplate.
+func (t *Template) UnmarshalJSON(data []byte) (err error) {
+	var text string
+	if err = json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid template: %s", data)
+	}
+	parsed, err := ParseTemplate(text)
+	if err != nil {
+		return
+	}
+	*t = *parsed
+	return
+}
@@ -0,0 +1,102 @@
+package status
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplate(t *testing.T) {
+	fields := Fields{"charge": 7, "load": 0.456, "name": "BAT0",
+		"remaining": 65*time.Minute + 20*time.Second, "long": "abcdefgh"}
+
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{"{{.charge}}%", "7%", true},
+		{"[{{lpad 3 .charge}}]", "[  7]", true},
+		{"[{{rpad 3 .charge}}]", "[7  ]", true},
+		{"{{fixed 2 .load}}", "0.46", true},
+		{"{{fixed 1 .charge}}", "7.0", true},
+		{"{{hm .remaining}}", "1:05", true},
+		{"{{truncate 4 .long}}", "abc…", true},
+		{"{{upper .name}} {{lower .name}}", "BAT0 bat0", true},
+		{"{{.missing}}", "", false},
+		{"{{fixed 2 .name}}", "", false},
+	}
+
+	for _, test := range tests {
+		tmpl, err := ParseTemplate(test.text)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%q): %v", test.text, err)
+		}
+		got, err := tmpl.Execute(fields)
+		if (err == nil) != test.ok {
+			t.Errorf("%q: got error %v", test.text, err)
+		} else if got != test.want {
+			t.Errorf("%q: got %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	if _, err := ParseTemplate("{{.charge"); err == nil {
+		t.Error("invalid template was parsed")
+	}
+}
+
+func TestApplyTemplates(t *testing.T) {
+	fields := Fields{"charge": 7}
+	red := ColorFromHex("#FF0000")
+
+	tests := []struct {
+		name        string
+		full, short string // "" for none
+		text, st    string
+		failed      bool
+	}{
+		{"none", "", "", "text", "short", false},
+		{"full", "{{.charge}}%", "", "7%", "short", false},
+		{"both", "{{.charge}}%", "{{.charge}}", "7%", "7", false},
+		{"failed", "{{.missing}}", "", "template error: ", "template error", true},
+		{"short failed", "{{.charge}}", "{{.missing}}", "template error: ", "template error", true},
+	}
+
+	parse := func(text string) *Template {
+		if text == "" {
+			return nil
+		}
+		return MustParseTemplate(text)
+	}
+	for _, test := range tests {
+		e := Element{FullText: "text", ShortText: "short", Color: &red,
+			Spans: []Span{{Text: "text"}}}
+		err := ApplyTemplates(&e, parse(test.full), parse(test.short), fields)
+		if (err != nil) != test.failed {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+		if !strings.HasPrefix(e.Text(), test.text) || e.ShortText != test.st {
+			t.Errorf("%s: got %q and %q, want %q and %q", test.name,
+				e.Text(), e.ShortText, test.text, test.st)
+		}
+		if test.failed && (e.Color != nil || e.ColorRole != RoleBad) {
+			t.Errorf("%s: error is not colored by the bad role", test.name)
+		}
+	}
+}
+
+func TestTemplateJSON(t *testing.T) {
+	var v struct {
+		Format *Template `json:"format"`
+	}
+	if err := json.Unmarshal([]byte(`{"format": "{{.charge}}%"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := v.Format.Execute(Fields{"charge": 7}); got != "7%" {
+		t.Errorf("got %q, want 7%%", got)
+	}
+	if err := json.Unmarshal([]byte(`{"format": "{{"}`), &v); err == nil {
+		t.Error("invalid template was accepted")
+	}
+}
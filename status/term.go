@@ -0,0 +1,180 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/sys/unix"
+)
+
+// DefaultTermWidth is used when the width of the terminal is unknown.
+const DefaultTermWidth = 80
+
+type term struct {
+	Bar
+	fd  int
+	out BarWriter
+}
+
+// NewTerm implements the Bar interface and renders the bar as a single
+// line using ANSI escape codes, which is redrawn in place on every
+// update. The width is read from the terminal fd on every write, and
+// the elements are fit within it using Budget.
+func NewTerm(fd int, out BarWriter) Bar {
+	return &term{fd: fd, out: out}
+}
+
+// width returns the width of the terminal in columns.
+func (b *term) width() int {
+	ws, err := unix.IoctlGetWinsize(b.fd, unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 {
+		return DefaultTermWidth
+	}
+	return int(ws.Col)
+}
+
+func ansiColor(c *Color, base int) string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", base, c.R, c.G, c.B)
+}
+
+// ansiStyle returns the escape codes setting the colors and
+// urgency of e.
+func ansiStyle(e Element) string {
+	style := ansiColor(e.Color, 38) + ansiColor(e.Background, 48)
+	if e.Urgent {
+		style += "\x1b[7m"
+	}
+	return style
+}
+
+// termStrip removes the C0 and C1 control characters from s, which
+// would move the cursor or start escape sequences. Tabs and newlines
+// are replaced by spaces.
+func termStrip(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// termStripElement returns e with the control characters removed from
+// its texts, see termStrip.
+func termStripElement(e Element) Element {
+	e.FullText = termStrip(e.FullText)
+	e.ShortText = termStrip(e.ShortText)
+	if len(e.Spans) > 0 {
+		spans := make([]Span, len(e.Spans))
+		for i, sp := range e.Spans {
+			sp.Text = termStrip(sp.Text)
+			spans[i] = sp
+		}
+		e.Spans = spans
+	}
+	return e
+}
+
+// termText returns the text of e formatted with escape codes, and
+// its width in columns.
+func termText(e Element) (string, int) {
+	var b strings.Builder
+	style := ansiStyle(e)
+	b.WriteString(style)
+
+	if len(e.Spans) == 0 {
+		b.WriteString(e.FullText)
+	}
+	for _, sp := range e.Spans {
+		spanStyle := ansiColor(sp.Color, 38) + ansiColor(sp.Background, 48)
+		if sp.Bold {
+			spanStyle += "\x1b[1m"
+		}
+		if sp.Italic {
+			spanStyle += "\x1b[3m"
+		}
+		if sp.Underline {
+			spanStyle += "\x1b[4m"
+		}
+		b.WriteString(spanStyle)
+		b.WriteString(sp.Text)
+		if spanStyle != "" {
+			b.WriteString("\x1b[0m" + style)
+		}
+	}
+
+	if style != "" {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String(), TextWidth(e.Text())
+}
+
+// Write ...
+func (b *term) Write(v []Element) (err error) {
+	// Leave the last column empty, some terminals wrap when it is
+	// written to.
+	width := b.width() - 1
+	stripped := make([]Element, len(v))
+	for i, e := range v {
+		stripped[i] = termStripElement(e)
+	}
+	v = Budget(stripped, width, 1)
+
+	type side struct {
+		texts []string
+		width int
+	}
+	groups := map[AlignStr]*side{
+		AlignLeft:   {},
+		AlignCenter: {},
+		AlignRight:  {},
+	}
+
+	for _, e := range v {
+		g, ok := groups[e.Alignment]
+		if !ok {
+			g = groups[AlignLeft]
+		}
+		text, width := termText(e)
+		if len(g.texts) > 0 {
+			g.width++ // separating space
+		}
+		g.texts = append(g.texts, text)
+		g.width += width
+	}
+	left, center, right := groups[AlignLeft], groups[AlignCenter], groups[AlignRight]
+
+	// Pad the groups into position, but never let them overlap.
+	centerPad := (width-center.width)/2 - left.width
+	if centerPad < 1 && left.width > 0 && len(center.texts) > 0 {
+		centerPad = 1
+	} else if centerPad < 0 {
+		centerPad = 0
+	}
+	rightPad := width - right.width - (left.width + centerPad + center.width)
+	if rightPad < 1 {
+		rightPad = 1
+	}
+
+	var s strings.Builder
+	s.WriteString("\r")
+	s.WriteString(strings.Join(left.texts, " "))
+	s.WriteString(strings.Repeat(" ", centerPad))
+	s.WriteString(strings.Join(center.texts, " "))
+	s.WriteString(strings.Repeat(" ", rightPad))
+	s.WriteString(strings.Join(right.texts, " "))
+	s.WriteString("\x1b[K") // clear the rest of the line
+
+	_, err = b.out.Write([]byte(s.String()))
+	if err != nil {
+		return
+	}
+	return b.out.Flush()
+}
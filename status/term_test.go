@@ -0,0 +1,75 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTermStrip(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"a\x1b[2Jb", "a[2Jb"},
+		{"bell\a", "bell"},
+		{"tab\tnew\nline", "tab new line"},
+		{"del\x7f", "del"},
+		{"csi\u009b31m", "csi31m"},
+		{"æøå ✓", "æøå ✓"},
+	}
+
+	for _, test := range tests {
+		if got := termStrip(test.in); got != test.want {
+			t.Errorf("termStrip(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+
+	e := Element{FullText: "a\x1b", ShortText: "\x07b",
+		Spans: []Span{{Text: "c\x1b"}}}
+	got := termStripElement(e)
+	if got.FullText != "a" || got.ShortText != "b" || got.Spans[0].Text != "c" {
+		t.Errorf("termStripElement: got %+v", got)
+	}
+	if e.Spans[0].Text != "c\x1b" {
+		t.Error("termStripElement modified the spans of the element")
+	}
+}
+
+func TestTermWidth(t *testing.T) {
+	red := ColorFromHex("#FF0000")
+	long := strings.Repeat("x", 50)
+
+	tests := []struct {
+		name  string
+		v     []Element
+		width int // visible columns, padded to the width
+	}{
+		{"empty", nil, 79},
+		{"left", []Element{{FullText: "abc"}}, 79},
+		{"right", []Element{{FullText: "abc", Alignment: AlignRight}}, 79},
+		{"colored right", []Element{{FullText: "abc", Color: &red, Alignment: AlignRight}}, 79},
+		{"all", []Element{{FullText: "a"}, {FullText: "b", Alignment: AlignCenter},
+			{FullText: "c", Alignment: AlignRight}}, 79},
+		{"too wide", []Element{{FullText: long}, {FullText: long, Alignment: AlignRight}}, 79},
+		{"too wide center", []Element{{FullText: long}, {FullText: "b", Alignment: AlignCenter},
+			{FullText: long, Alignment: AlignRight}}, 79},
+	}
+
+	// Escape sequences and the carriage return take up no columns.
+	escapes := regexp.MustCompile("\r|\x1b\\[[0-9;]*[A-Za-z]")
+	for _, test := range tests {
+		var buf bytes.Buffer
+		// Not a terminal, so DefaultTermWidth is used.
+		b := NewTerm(-1, bufio.NewWriter(&buf))
+		if err := b.Write(test.v); err != nil {
+			t.Fatal(err)
+		}
+		visible := escapes.ReplaceAllString(buf.String(), "")
+		if got := TextWidth(visible); got != test.width {
+			t.Errorf("%s: got %d columns (%q), want %d", test.name, got, visible, test.width)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package status
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Role is the meaning of a color, which is mapped to a
+// color by the Theme in use.
+type Role string
+
+const (
+	RoleGood      Role = "good"
+	RoleDegraded  Role = "degraded"
+	RoleBad       Role = "bad"
+	RoleIdle      Role = "idle"
+	RoleAccent    Role = "accent"
+	RoleSeparator Role = "separator"
+)
+
+// Theme maps roles to colors.
+type Theme map[Role]Color
+
+// Color returns the color of r, or nil if the theme does not have it.
+func (t Theme) Color(r Role) *Color {
+	c, ok := t[r]
+	if !ok {
+		return nil
+	}
+	return &c
+}
+
+// Themes contains the bundled themes by name.
+var Themes = map[string]Theme{
+	"default": {
+		RoleGood:      ColorFromHex("#6A9A3B"),
+		RoleDegraded:  ColorFromHex("#C7A13B"),
+		RoleBad:       ColorFromHex("#B82E34"),
+		RoleIdle:      ColorFromHex("#8A8B8C"),
+		RoleAccent:    ColorFromHex("#4F86C6"),
+		RoleSeparator: ColorFromHex("#555555"),
+	},
+	"gruvbox": {
+		RoleGood:      ColorFromHex("#B8BB26"),
+		RoleDegraded:  ColorFromHex("#FABD2F"),
+		RoleBad:       ColorFromHex("#FB4934"),
+		RoleIdle:      ColorFromHex("#A89984"),
+		RoleAccent:    ColorFromHex("#83A598"),
+		RoleSeparator: ColorFromHex("#665C54"),
+	},
+	"solarized": {
+		RoleGood:      ColorFromHex("#859900"),
+		RoleDegraded:  ColorFromHex("#B58900"),
+		RoleBad:       ColorFromHex("#DC322F"),
+		RoleIdle:      ColorFromHex("#93A1A1"),
+		RoleAccent:    ColorFromHex("#268BD2"),
+		RoleSeparator: ColorFromHex("#586E75"),
+	},
+	"nord": {
+		RoleGood:      ColorFromHex("#A3BE8C"),
+		RoleDegraded:  ColorFromHex("#EBCB8B"),
+		RoleBad:       ColorFromHex("#BF616A"),
+		RoleIdle:      ColorFromHex("#D8DEE9"),
+		RoleAccent:    ColorFromHex("#88C0D0"),
+		RoleSeparator: ColorFromHex("#4C566A"),
+	},
+}
+
+// DefaultTheme is used unless another theme is set.
+var DefaultTheme = Themes["default"]
+
+// ThemeNames returns the names of the bundled themes, sorted.
+func ThemeNames() (names []string) {
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// LookupTheme returns the bundled theme with the given name.
+func LookupTheme(name string) (Theme, error) {
+	t, ok := Themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme: %s", name)
+	}
+	return t, nil
+}
+
+// resolve replaces the roles of e with the colors of t.
+func (t Theme) resolve(e Element) Element {
+	if e.ColorRole != "" {
+		if c := t.Color(e.ColorRole); c != nil {
+			e.Color = c
+		}
+		e.ColorRole = ""
+	}
+	if e.BackgroundRole != "" {
+		if c := t.Color(e.BackgroundRole); c != nil {
+			e.Background = c
+		}
+		e.BackgroundRole = ""
+	}
+	return e
+}
+
+// SetTheme sets the theme used to color the elements which refer to
+// a Role instead of a color, DefaultTheme is used if not set.
+func (s *Status) SetTheme(t Theme) error {
+	if s.started {
+		return fmt.Errorf("cannot set theme: %w", ErrStarted)
+	}
+	s.theme = t
+	return nil
+}
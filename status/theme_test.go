@@ -0,0 +1,66 @@
+package status
+
+import (
+	"testing"
+)
+
+func TestThemeResolve(t *testing.T) {
+	red := ColorFromHex("#FF0000")
+	theme := Theme{RoleBad: ColorFromHex("#B82E34")}
+
+	tests := []struct {
+		name   string
+		e      Element
+		fg, bg string // "" for none
+	}{
+		{"none", Element{}, "", ""},
+		{"color", Element{Color: &red}, "#ff0000", ""},
+		{"role", Element{Color: &red, ColorRole: RoleBad}, "#b82e34", ""},
+		{"missing role", Element{Color: &red, ColorRole: RoleIdle}, "#ff0000", ""},
+		{"background role", Element{BackgroundRole: RoleBad}, "", "#b82e34"},
+	}
+
+	hex := func(c *Color) string {
+		if c == nil {
+			return ""
+		}
+		return c.Hex()
+	}
+	for _, test := range tests {
+		got := theme.resolve(test.e)
+		if hex(got.Color) != test.fg || hex(got.Background) != test.bg {
+			t.Errorf("%s: got %s, %s, want %s, %s", test.name,
+				hex(got.Color), hex(got.Background), test.fg, test.bg)
+		}
+		if got.ColorRole != "" || got.BackgroundRole != "" {
+			t.Errorf("%s: roles were not cleared", test.name)
+		}
+	}
+}
+
+func TestSeparatorColor(t *testing.T) {
+	grey := ColorFromHex("#555555")
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"dzen2", (&dzen2{opts: Dzen2Options{Separator: "|", SeparatorColor: &grey}}).separator(),
+			"^fg(#555555)|^fg()"},
+		{"xmobar", (&xmobar{opts: XmobarOptions{Separator: "|", SeparatorColor: &grey}}).separator(),
+			"<fc=#555555>|</fc>"},
+		{"tmux", (&tmux{opts: TmuxOptions{Separator: "|", SeparatorColor: &grey}}).separator(),
+			"#[fg=#555555]|#[default]"},
+		{"dwm", (&dwm{opts: DwmOptions{Separator: "|", SeparatorColor: &grey, Status2d: true}}).separator(),
+			"^c#555555^|^d^"},
+		{"dwm without status2d", (&dwm{opts: DwmOptions{Separator: "|", SeparatorColor: &grey}}).separator(),
+			"|"},
+		{"no color", (&tmux{opts: TmuxOptions{Separator: "|"}}).separator(), "|"},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, test.got, test.want)
+		}
+	}
+}
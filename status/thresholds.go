@@ -0,0 +1,115 @@
+package status
+
+import (
+	"sync"
+)
+
+// Threshold styles the elements of a widget whose value is within
+// [Min, Max). A nil Min or Max leaves that end of the range open.
+type Threshold struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+
+	Color      *Color `json:"color,omitempty"`
+	Background *Color `json:"background,omitempty"`
+	Urgent     bool   `json:"urgent,omitempty"`
+
+	// Roles are used instead of the colors if set, see Theme.
+	ColorRole      Role `json:"color_role,omitempty"`
+	BackgroundRole Role `json:"background_role,omitempty"`
+
+	// Icon is put in front of the text of the element.
+	Icon string `json:"icon,omitempty"`
+}
+
+// contains returns true if v is within the range of t
+// widened by margin in both directions.
+func (t Threshold) contains(v, margin float64) bool {
+	if t.Min != nil && v < *t.Min-margin {
+		return false
+	}
+	if t.Max != nil && v >= *t.Max+margin {
+		return false
+	}
+	return true
+}
+
+// Thresholds maps the value of a widget (eg. battery charge or CPU
+// usage) to the style of its elements. The first matching Threshold
+// is used, but with a Hysteresis the previously used Threshold is kept
+// until the value has left its range by more than Hysteresis, or is
+// more than Hysteresis into the range of an earlier Threshold. This
+// keeps the style from flapping when the value is at a boundary.
+type Thresholds struct {
+	Rules      []Threshold `json:"rules"`
+	Hysteresis float64     `json:"hysteresis,omitempty"`
+
+	mu   sync.Mutex
+	last map[string]int // index of the last rule used, by key
+}
+
+// Match returns the Threshold for v, or nil if there is none. Key
+// identifies the value for hysteresis, eg. the instance of the element.
+func (t *Thresholds) Match(key string, v float64) *Threshold {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	match := -1
+	for i, rule := range t.Rules {
+		if rule.contains(v, 0) {
+			match = i
+			break
+		}
+	}
+
+	if t.last == nil {
+		t.last = make(map[string]int)
+	}
+	if last, ok := t.last[key]; ok && last != match && last < len(t.Rules) &&
+		t.Rules[last].contains(v, t.Hysteresis) &&
+		(match == -1 || match > last || !t.Rules[match].contains(v, -t.Hysteresis)) {
+		match = last
+	}
+
+	if match == -1 {
+		delete(t.last, key)
+		return nil
+	}
+	t.last[key] = match
+	return &t.Rules[match]
+}
+
+// Apply styles e according to the Threshold matching v, see Match.
+// Fields left unset by the Threshold are not changed.
+func (t *Thresholds) Apply(key string, v float64, e *Element) {
+	rule := t.Match(key, v)
+	if rule == nil {
+		return
+	}
+	if rule.Color != nil {
+		e.Color = rule.Color
+		e.ColorRole = ""
+	}
+	if rule.Background != nil {
+		e.Background = rule.Background
+		e.BackgroundRole = ""
+	}
+	if rule.ColorRole != "" {
+		e.ColorRole = rule.ColorRole
+	}
+	if rule.BackgroundRole != "" {
+		e.BackgroundRole = rule.BackgroundRole
+	}
+	if rule.Urgent {
+		e.Urgent = true
+	}
+	if rule.Icon != "" {
+		e.FullText = rule.Icon + " " + e.FullText
+		if len(e.Spans) > 0 {
+			e.Spans = append([]Span{{Text: rule.Icon + " "}}, e.Spans...)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestThresholdsHysteresis(t *testing.T) {
+	var th Thresholds
+	err := json.Unmarshal([]byte(`{"hysteresis": 2, "rules": [
+		{"max": 15, "color": "red", "urgent": true, "icon": "!"},
+		{"max": 50, "color": "yellow"},
+		{"color": "green"}]}`), &th)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each step depends on the previous ones.
+	tests := []struct {
+		v    float64
+		want int // index of the rule, -1 for none
+	}{
+		{60, 2},
+		{51, 2},
+		{49, 2}, // within the hysteresis of green
+		{47, 1},
+		{14, 1}, // within the hysteresis of yellow
+		{12, 0},
+		{16, 0},
+		{17, 1},
+		{52, 2},
+		{49, 2},
+	}
+
+	for i, test := range tests {
+		got := -1
+		rule := th.Match("", test.v)
+		for j := range th.Rules {
+			if rule == &th.Rules[j] {
+				got = j
+			}
+		}
+		if got != test.want {
+			t.Errorf("step %d: Match(%v) = rule %d, want %d", i, test.v, got, test.want)
+		}
+	}
+}
+
+func TestThresholdsMatch(t *testing.T) {
+	min, max := 10.0, 20.0
+	th := &Thresholds{Rules: []Threshold{{Min: &min, Max: &max}}}
+
+	tests := []struct {
+		v  float64
+		ok bool
+	}{
+		{9.9, false},
+		{10, true},
+		{19.9, true},
+		{20, false},
+	}
+
+	for _, test := range tests {
+		// A key per value, so that there is no hysteresis.
+		key := fmt.Sprint(test.v)
+		if got := th.Match(key, test.v) != nil; got != test.ok {
+			t.Errorf("Match(%v) = %v, want %v", test.v, got, test.ok)
+		}
+	}
+
+	var none *Thresholds
+	if none.Match("", 1) != nil {
+		t.Error("nil Thresholds matched")
+	}
+}
+
+func TestThresholdsApply(t *testing.T) {
+	red := ColorFromHex("#FF0000")
+	th := &Thresholds{Rules: []Threshold{
+		{Color: &red, Urgent: true, Icon: "!", BackgroundRole: RoleBad},
+	}}
+
+	e := Element{FullText: "5%", ColorRole: RoleGood,
+		Spans: []Span{{Text: "5%"}}}
+	th.Apply("", 5, &e)
+
+	if e.Color != &red || e.ColorRole != "" || e.BackgroundRole != RoleBad || !e.Urgent {
+		t.Errorf("style not applied: %+v", e)
+	}
+	if e.FullText != "! 5%" || e.Text() != "! 5%" {
+		t.Errorf("got text %q and spans %q, want the icon in front", e.FullText, e.Text())
+	}
+}
@@ -0,0 +1,95 @@
+package status
+
+import (
+	"time"
+)
+
+// alignedCheckInterval is the longest an AlignedTicker sleeps before
+// checking whether the wall clock has jumped.
+const alignedCheckInterval = time.Second
+
+// jumpTolerance is how far the wall clock may drift from the monotonic
+// clock between two checks before it is considered a jump.
+const jumpTolerance = 100 * time.Millisecond
+
+// AlignedTicker is like time.Ticker, but ticks on the wall clock
+// boundaries of the interval in the local time zone, eg. exactly on
+// the minute for time.Minute and at midnight for 24 hours.
+//
+// Timers follow the monotonic clock, which does not advance while the
+// system is suspended and does not follow changes to the wall clock.
+// The ticker notices when the wall clock diverges from the monotonic
+// clock, after a resume or when the time is set, and then ticks right
+// away and re-aligns to the new time.
+type AlignedTicker struct {
+	C    <-chan time.Time
+	stop chan bool
+}
+
+// NewAlignedTicker returns a ticker which ticks on the boundaries of d,
+// which must be positive. Like time.Ticker, ticks are dropped if the
+// reader falls behind.
+func NewAlignedTicker(d time.Duration) *AlignedTicker {
+	if d <= 0 {
+		panic("non-positive interval for NewAlignedTicker")
+	}
+	c := make(chan time.Time, 1)
+	t := &AlignedTicker{C: c, stop: make(chan bool)}
+	go t.run(d, c)
+	return t
+}
+
+// Stop turns off the ticker, C is not closed.
+func (t *AlignedTicker) Stop() {
+	close(t.stop)
+}
+
+// nextBoundary returns the first boundary of d after now, in local time.
+func nextBoundary(now time.Time, d time.Duration) time.Time {
+	_, offset := now.Zone()
+	zone := time.Duration(offset) * time.Second
+	// Truncate aligns to the zero time in UTC, shift into local time.
+	return now.Add(zone).Truncate(d).Add(d).Add(-zone)
+}
+
+func (t *AlignedTicker) run(d time.Duration, c chan time.Time) {
+	// next has no monotonic reading (see Truncate), so comparing
+	// with it uses the wall clock.
+	next := nextBoundary(time.Now(), d)
+
+	// Stopped until it is reset below.
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		before := time.Now()
+		wait := next.Sub(before)
+		if wait > alignedCheckInterval {
+			wait = alignedCheckInterval
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-t.stop:
+			return
+		case <-timer.C:
+			break
+		}
+
+		now := time.Now()
+		drift := now.Round(0).Sub(before.Round(0)) - now.Sub(before)
+		jumped := drift > jumpTolerance || drift < -jumpTolerance
+		if !jumped && now.Before(next) {
+			continue
+		}
+
+		select {
+		case c <- now:
+			break
+		default:
+			break
+		}
+		next = nextBoundary(now, d)
+	}
+}
@@ -0,0 +1,63 @@
+package status
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBoundary(t *testing.T) {
+	oslo := time.FixedZone("CET", 3600)
+	india := time.FixedZone("IST", 5*3600+30*60)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		now  time.Time
+		d    time.Duration
+		want time.Time
+	}{
+		{time.Date(2024, 3, 14, 13, 7, 5, 500, time.UTC), time.Second,
+			time.Date(2024, 3, 14, 13, 7, 6, 0, time.UTC)},
+		{time.Date(2024, 3, 14, 13, 7, 5, 0, time.UTC), time.Minute,
+			time.Date(2024, 3, 14, 13, 8, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 14, 13, 7, 5, 0, time.UTC), time.Hour,
+			time.Date(2024, 3, 14, 14, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 14, 13, 7, 5, 0, time.UTC), day,
+			time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		// On a boundary, the next one is a whole interval away.
+		{time.Date(2024, 3, 14, 13, 8, 0, 0, time.UTC), time.Minute,
+			time.Date(2024, 3, 14, 13, 9, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 14, 13, 7, 5, 0, oslo), 10 * time.Second,
+			time.Date(2024, 3, 14, 13, 7, 10, 0, oslo)},
+		{time.Date(2024, 3, 14, 0, 30, 0, 0, oslo), day,
+			time.Date(2024, 3, 15, 0, 0, 0, 0, oslo)},
+		// Hours and days follow the half hour offset.
+		{time.Date(2024, 3, 14, 13, 45, 0, 0, india), time.Hour,
+			time.Date(2024, 3, 14, 14, 0, 0, 0, india)},
+		{time.Date(2024, 3, 14, 23, 59, 59, 0, india), day,
+			time.Date(2024, 3, 15, 0, 0, 0, 0, india)},
+	}
+
+	for _, test := range tests {
+		if got := nextBoundary(test.now, test.d); !got.Equal(test.want) {
+			t.Errorf("nextBoundary(%v, %v) = %v, want %v",
+				test.now, test.d, got, test.want)
+		}
+	}
+}
+
+func TestAlignedTicker(t *testing.T) {
+	d := 50 * time.Millisecond
+	ticker := NewAlignedTicker(d)
+	defer ticker.Stop()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case tick := <-ticker.C:
+			if off := tick.Sub(tick.Truncate(d)); off > d/2 {
+				t.Errorf("tick %v is %v after the boundary", tick, off)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no tick within a second")
+		}
+	}
+}
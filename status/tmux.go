@@ -0,0 +1,129 @@
+package status
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// TmuxOptions configures the tmux output.
+type TmuxOptions struct {
+	// Separator is written between elements, in SeparatorColor if
+	// set, eg. the color of RoleSeparator in the theme.
+	Separator      string
+	SeparatorColor *Color
+
+	// Option is the tmux option (eg. status-right) which is set on
+	// every update using tmux set-option -g. If empty the line is
+	// written to the output instead, for use with #(...).
+	Option string
+}
+
+type tmux struct {
+	Bar
+	opts TmuxOptions
+	out  BarWriter
+}
+
+// NewTmux implements the Bar interface and supports generating
+// tmux format strings.
+func NewTmux(opts TmuxOptions, out BarWriter) Bar {
+	return &tmux{opts: opts, out: out}
+}
+
+func tmuxEscape(s string) string {
+	return strings.Replace(s, "#", "##", -1)
+}
+
+// tmuxStyle returns the style directive for the given colors and
+// attributes, or an empty string if there is nothing to set.
+func tmuxStyle(fg, bg *Color, attrs ...string) string {
+	if fg != nil {
+		attrs = append(attrs, fmt.Sprintf("fg=%s", fg.Hex()))
+	}
+	if bg != nil {
+		attrs = append(attrs, fmt.Sprintf("bg=%s", bg.Hex()))
+	}
+	if len(attrs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("#[%s]", strings.Join(attrs, ","))
+}
+
+// tmuxSpans formats the spans of e, restoring the style of the
+// element after each span. Fonts are not supported.
+func tmuxSpans(e Element) string {
+	var b strings.Builder
+	for _, sp := range e.Spans {
+		var attrs []string
+		if sp.Bold {
+			attrs = append(attrs, "bold")
+		}
+		if sp.Italic {
+			attrs = append(attrs, "italics")
+		}
+		if sp.Underline {
+			attrs = append(attrs, "underscore")
+		}
+		style := tmuxStyle(sp.Color, sp.Background, attrs...)
+
+		b.WriteString(style)
+		b.WriteString(tmuxEscape(sp.Text))
+		if style != "" {
+			b.WriteString("#[default]")
+			b.WriteString(tmuxStyle(e.Color, e.Background))
+		}
+	}
+	return b.String()
+}
+
+// separator returns the separator in its color.
+func (b *tmux) separator() string {
+	if b.opts.SeparatorColor == nil {
+		return b.opts.Separator
+	}
+	return tmuxStyle(b.opts.SeparatorColor, nil) + b.opts.Separator + "#[default]"
+}
+
+// Write ...
+func (b *tmux) Write(v []Element) (err error) {
+	var s strings.Builder
+
+	sep := b.separator()
+	for i, e := range v {
+		if i > 0 {
+			s.WriteString(sep)
+		}
+
+		var attrs []string
+		if e.Urgent {
+			attrs = append(attrs, "reverse")
+		}
+		style := tmuxStyle(e.Color, e.Background, attrs...)
+
+		s.WriteString(style)
+		if len(e.Spans) > 0 {
+			s.WriteString(tmuxSpans(e))
+		} else {
+			s.WriteString(tmuxEscape(e.FullText))
+		}
+		if style != "" {
+			s.WriteString("#[default]")
+		}
+	}
+
+	if b.opts.Option != "" {
+		out, err := exec.Command("tmux", "set-option", "-g", b.opts.Option, s.String()).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("tmux: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	s.WriteByte('\n')
+	_, err = b.out.Write([]byte(s.String()))
+	if err != nil {
+		return
+	}
+	return b.out.Flush()
+}
@@ -1,8 +1,7 @@
 package status
 
 import (
-	"fmt"
-	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,26 +17,18 @@ func HBar(progress, size int, prune, other rune) string {
 	return string(v)
 }
 
-// ColorFromHex converts a hex color string (#RRGGBB) to a Color struct
-func ColorFromHex(hex string) (c Color) {
-	if len(hex) != 7 {
-		panic(fmt.Sprintf("%s is not a valid hex color: invalid length %d",
-			hex, len(hex)))
+// HBarText is like HBar, but the cells are strings such as icons
+// which may be more than one rune.
+func HBarText(progress, size int, full, empty string) string {
+	if progress < 0 {
+		progress = 0
+	} else if progress > size {
+		progress = size
 	}
-
-	var rgb [3]uint8
-	for i := 0; i < cap(rgb); i++ {
-		c, err := strconv.ParseUint(hex[2*i+1:2*i+3], 16, 8)
-		if err != nil {
-			nerr := fmt.Errorf("%s is not a valid hex color: %v", hex, err)
-			panic(nerr)
-		}
-		rgb[i] = uint8(c)
-	}
-	return Color{rgb[0], rgb[1], rgb[2]}
+	return strings.Repeat(full, progress) + strings.Repeat(empty, size-progress)
 }
 
-// Calls gen() every tick (timeout) until <-stop. On error the Error field
+// Calls gen() every tick (timeout) or refresh request until <-stop. On error the Error field
 // of the widget is set and the goroutine signifies it is 'done' and returns.
 func Generatorfunc(w *Widget, index int, ctx *GeneratorCtx,
 	tick <-chan time.Time, gen func() ([]Element, error)) {
@@ -49,7 +40,7 @@ func Generatorfunc(w *Widget, index int, ctx *GeneratorCtx,
 		ctx.Done <- true
 		return
 	}
-	ctx.Ch <- WidgetElem{index, prod}
+	ctx.Mailbox.Put(index, prod)
 
 	for {
 		select {
@@ -63,6 +54,9 @@ func Generatorfunc(w *Widget, index int, ctx *GeneratorCtx,
 				return
 			}
 			break
+
+		case <-ctx.Refresh[index]:
+			break
 		}
 
 		prod, err := gen()
@@ -72,6 +66,6 @@ func Generatorfunc(w *Widget, index int, ctx *GeneratorCtx,
 			ctx.Done <- true
 			return
 		}
-		ctx.Ch <- WidgetElem{index, prod}
+		ctx.Mailbox.Put(index, prod)
 	}
 }
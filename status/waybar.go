@@ -0,0 +1,88 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// WaybarBlock is the JSON object expected by the waybar custom
+// module with "return-type": "json".
+type WaybarBlock struct {
+	Text       string   `json:"text"`
+	Tooltip    string   `json:"tooltip,omitempty"`
+	Class      []string `json:"class,omitempty"`
+	Percentage *int     `json:"percentage,omitempty"`
+	Alt        string   `json:"alt,omitempty"`
+}
+
+type waybar struct {
+	Bar
+	out BarWriter
+}
+
+// NewWaybar implements the Bar interface and supports generating
+// output for a waybar custom module. All elements are combined into
+// a single block, so usually only a single widget is used per module.
+func NewWaybar(out BarWriter) Bar {
+	return &waybar{out: out}
+}
+
+// waybarText returns the text of e as pango markup.
+func waybarText(e Element) string {
+	if len(e.Spans) > 0 {
+		return PangoSpans(e.Spans)
+	}
+	if e.Markup == "pango" {
+		return e.FullText
+	}
+
+	sp := Span{Text: e.FullText, Color: e.Color, Background: e.Background}
+	return sp.Pango()
+}
+
+// Write ...
+func (b *waybar) Write(v []Element) (err error) {
+	var block WaybarBlock
+	var texts, tooltips []string
+	classes := make(map[string]bool)
+
+	addClass := func(class string) {
+		if class != "" && !classes[class] {
+			classes[class] = true
+			block.Class = append(block.Class, class)
+		}
+	}
+
+	for _, e := range v {
+		texts = append(texts, waybarText(e))
+		if e.Tooltip != "" {
+			tooltips = append(tooltips, e.Tooltip)
+		}
+		if block.Percentage == nil {
+			block.Percentage = e.Percentage
+		}
+		if block.Alt == "" {
+			block.Alt = e.Alt
+		}
+
+		addClass(e.Name)
+		if e.Urgent {
+			addClass("urgent")
+		}
+	}
+	block.Text = strings.Join(texts, " ")
+	block.Tooltip = strings.Join(tooltips, "\n")
+
+	data, err := json.Marshal(block)
+	if err != nil {
+		return fmt.Errorf("waybar: %v", err)
+	}
+	data = append(data, '\n')
+
+	_, err = b.out.Write(data)
+	if err != nil {
+		return
+	}
+	return b.out.Flush()
+}
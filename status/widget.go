@@ -1,13 +1,29 @@
 package status
 
+import (
+	"context"
+)
+
 // Widget contains information about a generator instance.
 type Widget struct {
-	Gen   Generator
+	// Gen is either a Generator or a ContextGenerator, see AddWidget.
+	Gen   interface{}
 	Error error // Only modified by generator
+
+	// Name is used to refer to the widget when requesting a refresh,
+	// in addition to the Name and Instance of its elements.
+	Name string
+
+	// Signal is the offset from SIGRTMIN of the realtime signal which
+	// forces the widget to regenerate, 0 disables it. (SIGRTMIN+Signal)
+	Signal int
+
+	// Priority is the default Priority of the widget's elements.
+	Priority int
 }
 
 // WidgetElem is returned from the generators to the
-// status instance through a channel.
+// status instance through the Mailbox.
 type WidgetElem struct {
 	Index int
 	e     []Element
@@ -20,24 +36,43 @@ type WidgetError struct {
 	Error error
 }
 
-// Generator is the interface all generators must implement.
+// Generator is the interface all generators must implement, unless
+// they implement ContextGenerator.
 type Generator interface {
 	Generate(w *Widget, index int, ctx *GeneratorCtx)
 }
 
-// GeneratorCtx is used to
+// ContextGenerator is implemented by generators which are run with a
+// context. Run publishes output through sink until ctx is cancelled,
+// after which it must return nil. A returned error is displayed in
+// place of the widget's elements.
+type ContextGenerator interface {
+	Run(ctx context.Context, sink Sink) error
+}
+
+// GeneratorCtx is used by generators to communicate with
+// the status, see Generator.
 type GeneratorCtx struct {
-	Ch         chan WidgetElem
+	Mailbox    *Mailbox
 	Stop, Done chan bool
 	Errorch    chan WidgetError
+
+	// Refresh contains one channel per widget (by index) which
+	// is used to force the generator to regenerate immediately.
+	Refresh []chan bool
 }
 
 // NewGeneratorCtx ...
 func NewGeneratorCtx(widgetcount int) GeneratorCtx {
+	refresh := make([]chan bool, widgetcount)
+	for i := range refresh {
+		refresh[i] = make(chan bool, 1)
+	}
 	return GeneratorCtx{
-		Ch:      make(chan WidgetElem),
+		Mailbox: NewMailbox(widgetcount),
 		Stop:    make(chan bool, widgetcount),
 		Done:    make(chan bool),
 		Errorch: make(chan WidgetError, widgetcount),
+		Refresh: refresh,
 	}
 }
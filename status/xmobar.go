@@ -0,0 +1,128 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// XmobarOptions configures the xmobar output.
+type XmobarOptions struct {
+	// Separator is written between elements, in SeparatorColor if
+	// set, eg. the color of RoleSeparator in the theme.
+	Separator      string
+	SeparatorColor *Color
+
+	// ClickCommand is run by xmobar when an element is clicked, see
+	// PolybarOptions. Empty disables actions.
+	ClickCommand string
+}
+
+type xmobar struct {
+	Bar
+	opts XmobarOptions
+	out  BarWriter
+}
+
+// NewXmobar implements the Bar interface and supports generating
+// output for xmobar. Tags are only interpreted by the
+// UnsafeStdinReader plugin.
+func NewXmobar(opts XmobarOptions, out BarWriter) Bar {
+	return &xmobar{opts: opts, out: out}
+}
+
+// xmobarEscape escapes s by wrapping it in a raw tag if it contains
+// a '<', the length of a raw tag is counted in characters.
+func xmobarEscape(s string) string {
+	if !strings.Contains(s, "<") {
+		return s
+	}
+	return fmt.Sprintf("<raw=%d:%s/>", utf8.RuneCountInString(s), s)
+}
+
+// xmobarColor returns the fc tag for the given colors, the foreground
+// is left empty when only the background is set.
+func xmobarColor(fg, bg *Color) (open, close string) {
+	switch {
+	case fg == nil && bg == nil:
+		return "", ""
+	case bg == nil:
+		return fmt.Sprintf("<fc=%s>", fg.Hex()), "</fc>"
+	case fg == nil:
+		return fmt.Sprintf("<fc=,%s>", bg.Hex()), "</fc>"
+	default:
+		return fmt.Sprintf("<fc=%s,%s>", fg.Hex(), bg.Hex()), "</fc>"
+	}
+}
+
+// xmobarSpans formats the spans of e. Bold, italic and underline
+// are not supported, fonts are given by their index in additionalFonts.
+func xmobarSpans(e Element) string {
+	var b strings.Builder
+	for _, sp := range e.Spans {
+		fg := sp.Color
+		if fg == nil && sp.Background != nil {
+			fg = e.Color
+		}
+		open, close := xmobarColor(fg, sp.Background)
+		b.WriteString(open)
+		if sp.Font != "" {
+			fmt.Fprintf(&b, "<fn=%s>", sp.Font)
+		}
+		b.WriteString(xmobarEscape(sp.Text))
+		if sp.Font != "" {
+			b.WriteString("</fn>")
+		}
+		b.WriteString(close)
+	}
+	return b.String()
+}
+
+// separator returns the separator in its color.
+func (b *xmobar) separator() string {
+	open, close := xmobarColor(b.opts.SeparatorColor, nil)
+	return open + b.opts.Separator + close
+}
+
+// Write ...
+func (b *xmobar) Write(v []Element) (err error) {
+	var s strings.Builder
+
+	sep := b.separator()
+	for i, e := range v {
+		if i > 0 {
+			s.WriteString(sep)
+		}
+
+		// The command of an action ends at the first backquote,
+		// which cannot be escaped.
+		clickable := b.opts.ClickCommand != "" && e.Name != "" &&
+			!strings.Contains(e.Name+e.Instance, "`")
+		if clickable {
+			for button := 1; button <= 3; button++ {
+				cmd := clickCommandLine(b.opts.ClickCommand, e, button)
+				fmt.Fprintf(&s, "<action=`%s` button=%d>", cmd, button)
+			}
+		}
+
+		open, close := xmobarColor(e.Color, e.Background)
+		s.WriteString(open)
+		if len(e.Spans) > 0 {
+			s.WriteString(xmobarSpans(e))
+		} else {
+			s.WriteString(xmobarEscape(e.FullText))
+		}
+		s.WriteString(close)
+
+		if clickable {
+			s.WriteString("</action></action></action>")
+		}
+	}
+	s.WriteByte('\n')
+
+	_, err = b.out.Write([]byte(s.String()))
+	if err != nil {
+		return
+	}
+	return b.out.Flush()
+}
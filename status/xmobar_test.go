@@ -0,0 +1,43 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestXmobar(t *testing.T) {
+	red := ColorFromHex("#FF0000")
+	black := ColorFromHex("#000000")
+	tests := []struct {
+		name string
+		e    Element
+		want string
+	}{
+		{"plain", Element{FullText: "a"}, "a\n"},
+		{"escaped", Element{FullText: "<b>"}, "<raw=3:<b>/>\n"},
+		{"color", Element{FullText: "a", Color: &red}, "<fc=#ff0000>a</fc>\n"},
+		{"both", Element{FullText: "a", Color: &red, Background: &black},
+			"<fc=#ff0000,#000000>a</fc>\n"},
+		{"background", Element{FullText: "a", Background: &black},
+			"<fc=,#000000>a</fc>\n"},
+		{"action", Element{Name: "n", FullText: "a"},
+			"<action=`c 1 'n' ''` button=1><action=`c 2 'n' ''` button=2>" +
+				"<action=`c 3 'n' ''` button=3>a</action></action></action>\n"},
+		{"backquote", Element{Name: "n`", FullText: "a"}, "a\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			out := bufio.NewWriter(&buf)
+			b := NewXmobar(XmobarOptions{ClickCommand: "c"}, out)
+			if err := b.Write([]Element{test.e}); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}